@@ -32,8 +32,11 @@ import (
 // 封装仓库和工作树以简化 Git 管理
 // 提供高级接口，带有健壮的异常处理
 type Client struct {
-	repo *git.Repository // Git repo instance // Git 仓库实例
-	tree *git.Worktree   // Working tree with ignore file support // 支持忽略文件的工作树
+	repo          *git.Repository            // Git repo instance // Git 仓库实例
+	tree          *git.Worktree              // Working tree with ignore file support // 支持忽略文件的工作树
+	subPath       string                     // Slash-separated DIR requested in New, relative to the worktree root // 在 New 中请求的目录，相对于工作树根目录并以斜杠分隔
+	auth          gogitassist.AuthProvider   // Credentials of remote operations, none when nil // 远程操作的凭据，为 nil 时不使用
+	hostKeyPolicy *gogitassist.HostKeyPolicy // SSH host key policy of remote operations, go-git default when nil // 远程操作的 SSH 主机密钥策略，为 nil 时使用 go-git 默认值
 }
 
 // NewClient creates a new Git client with specified repo and worktree
@@ -50,23 +53,32 @@ func NewClient(repo *git.Repository, tree *git.Worktree) *Client {
 	}
 }
 
-// New initializes a Git client at the specified project root path
+// New initializes a Git client at the specified project path
+// The path may be a subDIR of the worktree or a linked worktree, the enclosing repo is discovered
 // Opens existing repo and creates worktree with ignore file support
 // Returns configured client available to use
 //
-// New 在指定的项目根路径初始化 Git 客户端
+// New 在指定的项目路径初始化 Git 客户端
+// 路径可以是工作树的子目录或链接工作树，会自动查找所在的仓库
 // 打开现有仓库并创建支持忽略文件的工作树
 // 返回配置好的客户端，可以直接使用
-func New(root string) (*Client, error) {
+func New(path string) (*Client, error) {
+	// Walk up to the worktree root holding the .git entry
+	// 向上查找包含 .git 条目的工作树根目录
+	location, err := gogitassist.DiscoverRepo(path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
 	// Initialize repo and worktree with ignore file support
 	// 初始化仓库和工作树，支持忽略文件
-	repo, tree, err := gogitassist.NewRepoTreeWithIgnore(root)
+	repo, tree, err := gogitassist.NewRepoTreeWithIgnore(location.Root)
 	if err != nil {
 		return nil, erero.Wro(err)
 	}
 	// Create client with configured repo and worktree
 	// 使用配置的仓库和工作树创建客户端
 	client := NewClient(repo, tree)
+	client.subPath = location.SubPath
 	return client, nil
 }
 
@@ -88,6 +100,22 @@ func (c *Client) Tree() *git.Worktree {
 	return c.tree
 }
 
+// Root returns the absolute path of the worktree root
+//
+// Root 返回工作树根目录的绝对路径
+func (c *Client) Root() string {
+	return c.tree.Filesystem.Root()
+}
+
+// SubPath returns the DIR requested in New relative to the worktree root, slash-separated
+// Blank when the client was opened at the root or created with NewClient
+//
+// SubPath 返回在 New 中请求的目录相对于工作树根目录的路径，以斜杠分隔
+// 客户端在根目录打开或通过 NewClient 创建时为空
+func (c *Client) SubPath() string {
+	return c.subPath
+}
+
 // AddAll stages changes including new files, modifications and deletions
 // Equivalent to 'git add --all' operation with comprehensive change detection
 // Fails with an issue when the staging operation encounters problems
@@ -119,6 +147,36 @@ func (c *Client) Status() (git.Status, error) {
 	return status, nil
 }
 
+// StatusIn returns the status of files under the prefix DIR, like 'git status -- <prefix>'
+// Prefix is relative to the worktree root, pass SubPath() to scope to the DIR given in New
+// Hashes just the files under prefix, so large monorepos stay fast
+//
+// StatusIn 返回 prefix 目录下文件的状态，类似 'git status -- <prefix>'
+// prefix 相对于工作树根目录，传入 SubPath() 可限定到 New 中给定的目录
+// 仅对 prefix 下的文件计算哈希，因此大型单体仓库也能保持快速
+func (c *Client) StatusIn(prefix string) (git.Status, error) {
+	status, err := gogitassist.StatusIn(c.repo, c.tree, prefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return status, nil
+}
+
+// FastStatus returns the same status as Status, built for large repos
+// Trusts the stat data in the index like 'git status' and hashes changed candidates in parallel
+// Submodules are compared by their checked out commit, core.fileMode is not read, both as in Status
+//
+// FastStatus 返回与 Status 相同的状态，专为大型仓库构建
+// 与 'git status' 一样信任索引中的 stat 数据，并行计算变更候选文件的哈希
+// 与 Status 一样，子模块按其检出的提交进行比较，且不读取 core.fileMode
+func (c *Client) FastStatus() (git.Status, error) {
+	status, err := gogitassist.FastStatus(c.repo, c.tree, gogitassist.NewFastStatusOptions())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return status, nil
+}
+
 // CommitAll commits staged changes with the provided commit info
 // Creates a new commit with staged files and applies specified signature
 // Returns commit hash string, blank string when no changes exist
@@ -236,18 +294,38 @@ func (c *Client) checkCommitHash(commitHash plumbing.Hash) (string, error) {
 
 // IsLatestCommitPushedToRemote checks if HEAD has been pushed to specified remote
 // Compares HEAD hash with remote branch hash to decide push status
+// The remote branch is the configured upstream when it is on this remote, else the branch of the same name
 // Returns true when hashes match, false when remote branch not found
 //
 // IsLatestCommitPushedToRemote 检查 HEAD 是否已推送到指定的远程
 // 比较 HEAD 哈希与远程分支哈希来判断推送状态
+// 配置的上游位于此远程时以其为远程分支，否则使用同名分支
 // 哈希匹配时返回 true，未找到远程分支时返回 false
 func (c *Client) IsLatestCommitPushedToRemote(remoteName string) (bool, error) {
-	// Get current branch reference (HEAD)
-	// 获取当前分支引用（HEAD）
-	branchReference := done.VCE(c.repo.Head()).Nice()
+	// Get current branch reference (HEAD), an unborn HEAD has nothing pushed
+	// 获取当前分支引用（HEAD），未诞生的 HEAD 没有任何已推送内容
+	branchReference, err := c.repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return false, nil
+		}
+		return false, erero.Wro(err)
+	}
+	remoteReferenceName := plumbing.ReferenceName(fmt.Sprintf("refs/remotes/%s/%s", remoteName, branchReference.Name().Short()))
+	// Use the tracking ref of the upstream when it is on this remote
+	// 上游位于此远程时使用其跟踪引用
+	if branchReference.Name().IsBranch() {
+		upstream, err := gogitassist.GetUpstream(c.repo, branchReference.Name().Short())
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		if upstream != nil && upstream.Remote == remoteName {
+			remoteReferenceName = upstream.TrackingRef
+		}
+	}
 	// Get remote branch hash to compare
 	// 获取远程分支哈希进行比较
-	remoteReference, err := c.repo.Reference(plumbing.ReferenceName(fmt.Sprintf("refs/remotes/%s/%s", remoteName, branchReference.Name().Short())), false)
+	remoteReference, err := c.repo.Reference(remoteReferenceName, false)
 	if err != nil {
 		// Remote reference not found (branch not pushed yet)
 		// 远程引用未找到（分支尚未推送）
@@ -262,13 +340,37 @@ func (c *Client) IsLatestCommitPushedToRemote(remoteName string) (bool, error) {
 }
 
 // IsLatestCommitPushed checks if HEAD has been pushed to configured remotes
-// Iterates through remotes and checks matching commit hashes
+// Checks just the upstream when the current branch has one on a remote
+// Else iterates through remotes and checks matching commit hashes
 // Returns true when HEAD exists in some remote, false when not pushed
 //
 // IsLatestCommitPushed 检查 HEAD 是否已推送到配置的远程
-// 遍历远程并检查匹配的提交哈希
+// 当前分支在远程上配置了上游时仅检查该上游
+// 否则遍历远程并检查匹配的提交哈希
 // 当 HEAD 存在于某个远程时返回 true，未推送时返回 false
 func (c *Client) IsLatestCommitPushed() (bool, error) {
+	// Check the upstream of the current branch when configured, an unborn HEAD has nothing pushed
+	// 当前分支配置了上游时检查该上游，未诞生的 HEAD 没有任何已推送内容
+	branchReference, err := c.repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return false, nil
+		}
+		return false, erero.Wro(err)
+	}
+	if branchReference.Name().IsBranch() {
+		upstream, err := gogitassist.GetUpstream(c.repo, branchReference.Name().Short())
+		if err != nil {
+			return false, erero.Wro(err)
+		}
+		if upstream != nil && upstream.Remote != "." {
+			pushed, err := c.IsLatestCommitPushedToRemote(upstream.Remote)
+			if err != nil {
+				return false, erero.Wro(err)
+			}
+			return pushed, nil
+		}
+	}
 	// Get all configured remote repos
 	// 获取所有配置的远程仓库
 	remotes, err := c.repo.Remotes()
@@ -0,0 +1,66 @@
+package gogit
+
+import (
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/yyle88/erero"
+)
+
+// SetAuth sets the provider giving credentials of remote operations
+// Use gogitassist.NewAuthRegistry to pick providers per remote URL pattern, nil removes credentials
+//
+// SetAuth 设置为远程操作提供凭据的提供者
+// 使用 gogitassist.NewAuthRegistry 按远程 URL 模式选择提供者，传入 nil 时移除凭据
+func (c *Client) SetAuth(auth gogitassist.AuthProvider) *Client {
+	c.auth = auth
+	return c
+}
+
+// SetHostKeyPolicy sets how SSH host keys get verified in remote operations
+// Nil restores the go-git default, which reads the default known_hosts files
+//
+// SetHostKeyPolicy 设置远程操作中 SSH 主机密钥的校验方式
+// 传入 nil 时恢复 go-git 默认行为，即读取默认的 known_hosts 文件
+func (c *Client) SetHostKeyPolicy(hostKeyPolicy *gogitassist.HostKeyPolicy) *Client {
+	c.hostKeyPolicy = hostKeyPolicy
+	return c
+}
+
+// AuthForURL returns the credentials of a remote URL picked by the auth provider
+// SSH credentials carry the host key policy, SSH URLs without credentials get the SSH agent then
+// Returns nil auth when neither provider nor policy is set, or no provider gives HTTP credentials
+//
+// AuthForURL 返回认证提供者为远程 URL 选出的凭据
+// SSH 凭据会携带主机密钥策略，此时缺少凭据的 SSH URL 会使用 SSH agent
+// 既未设置提供者也未设置策略，或没有提供者给出 HTTP 凭据时返回 nil 认证
+func (c *Client) AuthForURL(remoteURL string) (transport.AuthMethod, error) {
+	if c.auth == nil && c.hostKeyPolicy == nil {
+		return nil, nil
+	}
+	parsed, err := gogitassist.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	auth, err := gogitassist.ResolveAuthMethod(c.auth, c.hostKeyPolicy, parsed)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return auth, nil
+}
+
+// AuthForRemote returns the credentials of the fetch URL of the specified remote
+// Pushing goes to the push URL, so use AuthForURL with GetRemotePushURL then
+//
+// AuthForRemote 返回指定远程拉取 URL 的凭据
+// 推送使用推送 URL，此时请将 AuthForURL 与 GetRemotePushURL 配合使用
+func (c *Client) AuthForRemote(remoteName string) (transport.AuthMethod, error) {
+	remoteURL, err := c.GetRemoteFetchURL(remoteName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	auth, err := c.AuthForURL(remoteURL)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return auth, nil
+}
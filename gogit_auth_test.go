@@ -0,0 +1,68 @@
+package gogit_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-xlan/gogit"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClient_AuthForRemote verifies resolving credentials of a remote through the auth registry
+// Should give nil auth without provider and pick the rule matching the remote URL
+//
+// TestClient_AuthForRemote 验证通过认证注册表解析远程的凭据
+// 未设置提供者时应返回 nil 认证，并选择与远程 URL 匹配的规则
+func TestClient_AuthForRemote(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+
+	client, err := gogit.New(tempDIR)
+	require.NoError(t, err)
+	require.NoError(t, gogitassist.AddRemote(client.Repo(), "origin", "https://github.com/example/repo.git"))
+
+	auth, err := client.AuthForRemote("origin")
+	require.NoError(t, err)
+	require.Nil(t, auth)
+
+	client.SetAuth(gogitassist.NewAuthRegistry().
+		Add("gitlab.com", gogitassist.NewTokenAuth("gitlab-token")).
+		Add("github.com/example", gogitassist.NewBasicAuth("account", "secret")))
+
+	auth, err = client.AuthForRemote("origin")
+	require.NoError(t, err)
+	require.Equal(t, &http.BasicAuth{Username: "account", Password: "secret"}, auth)
+
+	auth, err = client.AuthForURL("https://gitlab.com/group/project.git")
+	require.NoError(t, err)
+	require.Equal(t, &http.BasicAuth{Username: "x-access-token", Password: "gitlab-token"}, auth)
+}
+
+// TestClient_SetHostKeyPolicy verifies SSH credentials of a remote carry the host key policy
+//
+// TestClient_SetHostKeyPolicy 验证远程的 SSH 凭据携带主机密钥策略
+func TestClient_SetHostKeyPolicy(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+
+	client, err := gogit.New(tempDIR)
+	require.NoError(t, err)
+	require.NoError(t, gogitassist.AddRemote(client.Repo(), "origin", "git@github.com:example/repo.git"))
+
+	var provided *gitssh.Password
+	client.SetAuth(gogitassist.AuthProviderFunc(func(remoteURL *gogitassist.RemoteURL) (transport.AuthMethod, error) {
+		provided = &gitssh.Password{User: remoteURL.User, Password: "secret"}
+		return provided, nil
+	}))
+	_, err = client.AuthForRemote("origin")
+	require.NoError(t, err)
+	require.Nil(t, provided.HostKeyCallback)
+
+	client.SetHostKeyPolicy(gogitassist.NewHostKeyPolicy().KnownHostsPaths(filepath.Join(tempDIR, "known_hosts")).AcceptNew(true))
+	auth, err := client.AuthForRemote("origin")
+	require.NoError(t, err)
+	require.Same(t, provided, auth)
+	require.NotNil(t, provided.HostKeyCallback)
+}
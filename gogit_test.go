@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-xlan/gogit"
 	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/stretchr/testify/require"
@@ -50,6 +51,40 @@ func TestNewClient(t *testing.T) {
 	t.Log(neatjsons.S(status))
 }
 
+// TestClient_FastStatus tests that fast status agrees with Status
+//
+// TestClient_FastStatus 测试快速状态与 Status 一致
+func TestClient_FastStatus(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "README.md"), []byte("# Test Project v2\n"), 0644))
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "main.go"), []byte("package main\n"), 0644))
+
+	client := rese.P1(gogit.New(tempDIR))
+	status, err := client.FastStatus()
+	require.NoError(t, err)
+	require.Equal(t, client.Must().Status(), status)
+	require.Len(t, status, 2)
+}
+
+// TestNew_SubPath tests opening a client from a subDIR of the worktree
+// Verifies the client keeps the worktree root and the requested subPath
+//
+// TestNew_SubPath 测试从工作树的子目录打开客户端
+// 验证客户端保留工作树根目录和所请求的子路径
+func TestNew_SubPath(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+	subDIR := filepath.Join(tempDIR, "docs", "guide")
+	must.Done(os.MkdirAll(subDIR, 0755))
+
+	client, err := gogit.New(subDIR)
+	require.NoError(t, err)
+	require.Equal(t, tempDIR, client.Root())
+	require.Equal(t, "docs/guide", client.SubPath())
+
+	client = rese.P1(gogit.New(tempDIR))
+	require.Equal(t, "", client.SubPath())
+}
+
 // TestClient_IsLatestCommitPushedToRemote tests remote push status detection
 // Verifies the function that checks if HEAD commit exists in specified remote
 // Uses production repo since it needs a functioning remote connection
@@ -83,6 +118,30 @@ func TestClient_IsLatestCommitPushedToRemote_NotExist(t *testing.T) {
 	require.False(t, matched)
 }
 
+// TestClient_IsLatestCommitPushed_UnbornHead verifies a repo without commits reports nothing pushed
+// Should return false without error instead of panicking on the missing HEAD
+//
+// TestClient_IsLatestCommitPushed_UnbornHead 验证没有提交的仓库报告未推送
+// 应该返回 false 且不返回错误，而不是因缺少 HEAD 而 panic
+func TestClient_IsLatestCommitPushed_UnbornHead(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	rese.P1(gogitassist.InitRepo(tempDIR))
+
+	client, err := gogit.New(tempDIR)
+	require.NoError(t, err)
+
+	pushed, err := client.IsLatestCommitPushed()
+	require.NoError(t, err)
+	require.False(t, pushed)
+
+	pushed, err = client.IsLatestCommitPushedToRemote("origin")
+	require.NoError(t, err)
+	require.False(t, pushed)
+}
+
 // TestClient_IsLatestCommitPushed tests push status across configured remotes
 // Verifies detection of commit push state when checking against all remotes
 // Uses production repo since it needs a functioning remote connection
@@ -295,3 +354,34 @@ func TestClient_AmendCommit_Success(t *testing.T) {
 	require.Equal(t, "Amended commit message", commitObj.Message)
 	require.Equal(t, "Amended Person", commitObj.Author.Name)
 }
+
+// TestClient_IsLatestCommitPushed_Upstream verifies push checks follow the configured upstream
+// The upstream has a name differing from the local branch, which the name guess misses
+//
+// TestClient_IsLatestCommitPushed_Upstream 验证推送检查遵循配置的上游
+// 上游名称与本地分支不同，按名称猜测无法找到
+func TestClient_IsLatestCommitPushed_Upstream(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+
+	client, err := gogit.New(tempDIR)
+	require.NoError(t, err)
+	require.NoError(t, gogitassist.AddRemote(client.Repo(), "origin", "https://github.com/example/repo.git"))
+	head := rese.P1(client.Repo().Head())
+	must.Done(client.Repo().Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "release"), head.Hash())))
+
+	pushed, err := client.IsLatestCommitPushed()
+	require.NoError(t, err)
+	require.False(t, pushed)
+
+	require.NoError(t, client.SetUpstream(head.Name().Short(), "origin", "release"))
+	upstream, err := client.GetUpstream(head.Name().Short())
+	require.NoError(t, err)
+	require.Equal(t, plumbing.NewRemoteReferenceName("origin", "release"), upstream.TrackingRef)
+
+	pushed, err = client.IsLatestCommitPushedToRemote("origin")
+	require.NoError(t, err)
+	require.True(t, pushed)
+	pushed, err = client.IsLatestCommitPushed()
+	require.NoError(t, err)
+	require.True(t, pushed)
+}
@@ -2,6 +2,7 @@ package gogit
 
 import (
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/yyle88/erero"
 )
 
@@ -24,6 +25,31 @@ func (c *Client) GetCurrentBranch() (string, error) {
 	return head.Name().Short(), nil
 }
 
+// SetUpstream sets the upstream of a local branch to remoteBranch on remote
+// Writes branch.<name>.remote and branch.<name>.merge, like git branch --set-upstream-to
+//
+// SetUpstream 将本地分支的上游设置为 remote 上的 remoteBranch
+// 写入 branch.<name>.remote 和 branch.<name>.merge，类似 git branch --set-upstream-to
+func (c *Client) SetUpstream(branch string, remote string, remoteBranch string) error {
+	if err := gogitassist.SetUpstream(c.repo, branch, remote, remoteBranch); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// GetUpstream returns the upstream of a local branch read from branch.<name>.remote and merge
+// Returns nil when the branch has no upstream configured
+//
+// GetUpstream 返回从 branch.<name>.remote 和 merge 读取的本地分支上游
+// 分支未配置上游时返回 nil
+func (c *Client) GetUpstream(branch string) (*gogitassist.Upstream, error) {
+	upstream, err := gogitassist.GetUpstream(c.repo, branch)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return upstream, nil
+}
+
 // GetLatestCommit returns the latest commit object from HEAD
 // Retrieves HEAD commit to inspect details such as message, signature, and timestamp
 // Returns complete commit object with metadata included
@@ -66,6 +92,19 @@ func (c *Client) HasChanges() (bool, error) {
 	return len(status) > 0, nil
 }
 
+// HasChangesIn checks if uncommitted changes exist under the prefix DIR
+// Scoped counterpart of HasChanges, see StatusIn about prefix
+//
+// HasChangesIn 检查 prefix 目录下是否存在未提交的更改
+// HasChanges 的限定范围版本，关于 prefix 参见 StatusIn
+func (c *Client) HasChangesIn(prefix string) (bool, error) {
+	status, err := c.StatusIn(prefix)
+	if err != nil {
+		return false, erero.Wro(err)
+	}
+	return len(status) > 0, nil
+}
+
 // GetRemoteURL returns the URL of the specified remote
 // Retrieves URL from remote config using the given name
 // Returns error when remote not found or has no URLs configured
@@ -89,6 +128,62 @@ func (c *Client) GetRemoteURL(remoteName string) (string, error) {
 	return urls[0], nil
 }
 
+// GetRemoteFetchURL returns the first fetch URL (remote.<name>.url) of the specified remote
+// Returns error when remote not found or has no URLs configured
+//
+// GetRemoteFetchURL 返回指定远程的第一个拉取 URL（remote.<name>.url）
+// 未找到远程或未配置 URL 时返回错误
+func (c *Client) GetRemoteFetchURL(remoteName string) (string, error) {
+	remoteURL, err := gogitassist.GetRemoteFetchURL(c.repo, remoteName)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return remoteURL, nil
+}
+
+// GetRemotePushURL returns the first push URL (remote.<name>.pushurl) of the specified remote
+// Falls back to the fetch URL when no push URL is configured, as git push does
+//
+// GetRemotePushURL 返回指定远程的第一个推送 URL（remote.<name>.pushurl）
+// 未配置推送 URL 时与 git push 一样回退到拉取 URL
+func (c *Client) GetRemotePushURL(remoteName string) (string, error) {
+	remoteURL, err := gogitassist.GetRemotePushURL(c.repo, remoteName)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return remoteURL, nil
+}
+
+// GetParsedRemoteURL returns the fetch URL of the specified remote, parsed
+// Gives host, owner and repo name, with helpers converting between SSH and HTTPS forms
+//
+// GetParsedRemoteURL 返回指定远程解析后的拉取 URL
+// 提供主机、所有者和仓库名，以及在 SSH 和 HTTPS 形式之间转换的辅助方法
+func (c *Client) GetParsedRemoteURL(remoteName string) (*gogitassist.RemoteURL, error) {
+	remoteURL, err := c.GetRemoteFetchURL(remoteName)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	res, err := gogitassist.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return res, nil
+}
+
+// ListRemotes returns the remotes with all fetch URLs, push URLs and refspecs
+// Remotes keep the config file order
+//
+// ListRemotes 返回远程及其全部拉取 URL、推送 URL 和 refspec
+// 远程保持配置文件中的顺序
+func (c *Client) ListRemotes() ([]*gogitassist.RemoteInfo, error) {
+	remotes, err := gogitassist.ListRemotes(c.repo)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return remotes, nil
+}
+
 // GetFirstRemoteURL returns the URL of the first available remote
 // Returns error when no remotes exist or no URLs configured
 // Returns error when fetching remotes fails
@@ -1,11 +1,16 @@
 package gogit_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/go-xlan/gogit"
 	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
 )
 
 // TestClient_GetCurrentBranch verifies getting current branch name
@@ -61,6 +66,32 @@ func TestClient_HasChanges(t *testing.T) {
 	t.Log("has changes:", hasChanges)
 }
 
+// TestClient_HasChangesIn verifies change checks scoped to a subDIR
+// Should see changes inside the scope and ignore changes elsewhere
+//
+// TestClient_HasChangesIn 验证限定到子目录的变更检查
+// 应该发现范围内的变更并忽略其他位置的变更
+func TestClient_HasChangesIn(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+	must.Done(os.MkdirAll(filepath.Join(tempDIR, "service-a"), 0755))
+	must.Done(os.WriteFile(filepath.Join(tempDIR, "service-a", "main.go"), []byte("package main\n"), 0644))
+
+	client, err := gogit.New(filepath.Join(tempDIR, "service-a"))
+	require.NoError(t, err)
+
+	hasChanges, err := client.HasChangesIn(client.SubPath())
+	require.NoError(t, err)
+	require.True(t, hasChanges)
+
+	hasChanges, err = client.HasChangesIn("service-b")
+	require.NoError(t, err)
+	require.False(t, hasChanges)
+
+	status, err := client.StatusIn("service-a")
+	require.NoError(t, err)
+	require.Equal(t, git.Untracked, status.File("service-a/main.go").Worktree)
+}
+
 // TestClient_GetRemoteURL verifies getting remote URL
 // Should return URL matching the specified remote name
 //
@@ -102,3 +133,54 @@ func TestClient_GetFirstRemoteURL(t *testing.T) {
 	require.Equal(t, "https://github.com/example/repo.git", remoteURL)
 	t.Log("first remote URL:", remoteURL)
 }
+
+// TestClient_GetRemotePushURL verifies fetch URL and push URL are returned apart
+// Push URL should fall back to the fetch URL when no pushurl is configured
+//
+// TestClient_GetRemotePushURL 验证分别返回拉取 URL 和推送 URL
+// 未配置 pushurl 时推送 URL 应回退到拉取 URL
+func TestClient_GetRemotePushURL(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+
+	client, err := gogit.New(tempDIR)
+	require.NoError(t, err)
+	require.NoError(t, gogitassist.AddRemote(client.Repo(), "origin", "https://github.com/example/repo.git"))
+
+	pushURL, err := client.GetRemotePushURL("origin")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/example/repo.git", pushURL)
+
+	require.NoError(t, gogitassist.SetRemotePushURLs(client.Repo(), "origin", "git@github.com:example/repo.git"))
+
+	fetchURL, err := client.GetRemoteFetchURL("origin")
+	require.NoError(t, err)
+	require.Equal(t, "https://github.com/example/repo.git", fetchURL)
+	pushURL, err = client.GetRemotePushURL("origin")
+	require.NoError(t, err)
+	require.Equal(t, "git@github.com:example/repo.git", pushURL)
+
+	remotes, err := client.ListRemotes()
+	require.NoError(t, err)
+	require.Len(t, remotes, 1)
+	t.Log(neatjsons.S(remotes))
+}
+
+// TestClient_GetParsedRemoteURL verifies parsing the fetch URL of a remote
+//
+// TestClient_GetParsedRemoteURL 验证解析远程的拉取 URL
+func TestClient_GetParsedRemoteURL(t *testing.T) {
+	tempDIR := setupTestRepo(t)
+
+	client, err := gogit.New(tempDIR)
+	require.NoError(t, err)
+	require.NoError(t, gogitassist.AddRemote(client.Repo(), "origin", "git@github.com:example/repo.git"))
+
+	remoteURL, err := client.GetParsedRemoteURL("origin")
+	require.NoError(t, err)
+	require.Equal(t, "github.com", remoteURL.Host)
+	require.Equal(t, "example", remoteURL.Owner)
+	require.Equal(t, "repo", remoteURL.Repo)
+
+	_, err = client.GetParsedRemoteURL("upstream")
+	require.Error(t, err)
+}
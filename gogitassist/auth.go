@@ -0,0 +1,265 @@
+package gogitassist
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/yyle88/erero"
+)
+
+// AuthProvider supplies credentials of a remote URL
+// Returns nil auth and nil error when it has no credentials matching the URL,
+// so the next provider gets its turn
+//
+// AuthProvider 为远程 URL 提供凭据
+// 没有与 URL 匹配的凭据时返回 nil 认证和 nil 错误，
+// 以便轮到下一个提供者
+type AuthProvider interface {
+	AuthMethod(remoteURL *RemoteURL) (transport.AuthMethod, error)
+}
+
+// AuthProviderFunc adapts a function to AuthProvider
+//
+// AuthProviderFunc 将函数适配为 AuthProvider
+type AuthProviderFunc func(remoteURL *RemoteURL) (transport.AuthMethod, error)
+
+// AuthMethod calls the function
+//
+// AuthMethod 调用该函数
+func (f AuthProviderFunc) AuthMethod(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+	return f(remoteURL)
+}
+
+// NewSSHAgentAuth returns a provider using the keys of the running SSH agent, with ssh URLs
+// User blank means the user of the URL, else git
+// No agent running, SSH_AUTH_SOCK being unset or its socket gone, gives no credentials
+//
+// NewSSHAgentAuth 返回使用正在运行的 SSH agent 中密钥的提供者，用于 ssh URL
+// user 为空表示使用 URL 中的用户，否则使用 git
+// 没有运行中的 agent（SSH_AUTH_SOCK 未设置或其套接字不存在）时不提供凭据
+func NewSSHAgentAuth(user string) AuthProvider {
+	return AuthProviderFunc(func(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+		if remoteURL.Scheme != "ssh" {
+			return nil, nil
+		}
+		// Windows talks to Pageant instead of a socket, so the check applies elsewhere
+		// Windows 通过 Pageant 而非套接字通信，因此仅在其他系统上检查
+		if runtime.GOOS != "windows" {
+			socketPath := os.Getenv("SSH_AUTH_SOCK")
+			if socketPath == "" {
+				return nil, nil
+			}
+			if _, err := os.Stat(socketPath); os.IsNotExist(err) {
+				return nil, nil
+			}
+		}
+		auth, err := ssh.NewSSHAgentAuth(sshAuthUser(user, remoteURL))
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return auth, nil
+	})
+}
+
+// NewSSHKeyAuth returns a provider using a private key file, with ssh URLs
+// Passphrase decrypts the key, blank with unencrypted keys
+// User blank means the user of the URL, else git
+//
+// NewSSHKeyAuth 返回使用私钥文件的提供者，用于 ssh URL
+// passphrase 用于解密私钥，未加密的私钥为空
+// user 为空表示使用 URL 中的用户，否则使用 git
+func NewSSHKeyAuth(user string, keyPath string, passphrase string) AuthProvider {
+	return AuthProviderFunc(func(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+		if remoteURL.Scheme != "ssh" {
+			return nil, nil
+		}
+		auth, err := ssh.NewPublicKeysFromFile(sshAuthUser(user, remoteURL), keyPath, passphrase)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return auth, nil
+	})
+}
+
+// sshAuthUser picks the given user, then the user of the URL, then git
+//
+// sshAuthUser 依次选择给定用户、URL 中的用户、git
+func sshAuthUser(user string, remoteURL *RemoteURL) string {
+	if user != "" {
+		return user
+	}
+	if remoteURL.User != "" {
+		return remoteURL.User
+	}
+	return "git"
+}
+
+// NewBasicAuth returns a provider using HTTP basic auth, with http and https URLs
+//
+// NewBasicAuth 返回使用 HTTP basic 认证的提供者，用于 http 和 https URL
+func NewBasicAuth(username string, password string) AuthProvider {
+	return AuthProviderFunc(func(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+		if !isHTTPScheme(remoteURL) {
+			return nil, nil
+		}
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	})
+}
+
+// NewTokenAuth returns a provider sending an access token, with http and https URLs
+// The token goes as basic auth password, which GitHub, GitLab, Gitea and Bitbucket accept,
+// the username being a placeholder since those hosts ignore it
+//
+// NewTokenAuth 返回发送访问令牌的提供者，用于 http 和 https URL
+// 令牌作为 basic 认证密码发送，GitHub、GitLab、Gitea 和 Bitbucket 均接受这种方式，
+// 这些主机会忽略用户名，因此用户名只是占位
+func NewTokenAuth(token string) AuthProvider {
+	return NewBasicAuth("x-access-token", token)
+}
+
+// isHTTPScheme checks if the URL uses http or https
+//
+// isHTTPScheme 检查 URL 是否使用 http 或 https
+func isHTTPScheme(remoteURL *RemoteURL) bool {
+	return remoteURL.Scheme == "http" || remoteURL.Scheme == "https"
+}
+
+// ResolveAuthMethod returns the credentials of the URL with the host key policy applied
+// Provider and policy may be nil, with SSH URLs lacking credentials the SSH agent is used, as go-git does,
+// so the policy still covers the connection
+//
+// ResolveAuthMethod 返回 URL 的凭据，并应用主机密钥策略
+// provider 和 policy 均可为 nil，SSH URL 缺少凭据时与 go-git 一样使用 SSH agent，
+// 使策略仍然作用于该连接
+func ResolveAuthMethod(provider AuthProvider, policy *HostKeyPolicy, remoteURL *RemoteURL) (transport.AuthMethod, error) {
+	var auth transport.AuthMethod
+	if provider != nil {
+		var err error
+		if auth, err = provider.AuthMethod(remoteURL); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if policy == nil || remoteURL.Scheme != "ssh" {
+		return auth, nil
+	}
+	if auth == nil {
+		var err error
+		if auth, err = ssh.DefaultAuthBuilder(sshAuthUser("", remoteURL)); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if err := policy.Apply(auth, remoteURL); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return auth, nil
+}
+
+// AuthRegistry picks credentials of a remote URL from providers bound to URL patterns
+// Rules are tried in the order added, the first provider giving credentials wins
+// Implements AuthProvider, so registries can nest
+//
+// AuthRegistry 根据绑定到 URL 模式的提供者为远程 URL 选择凭据
+// 规则按添加顺序尝试，第一个给出凭据的提供者胜出
+// 实现了 AuthProvider，因此注册表可以嵌套
+type AuthRegistry struct {
+	rules []*authRule // Pattern bound providers in order // 按顺序绑定模式的提供者
+}
+
+// authRule binds a provider to a URL pattern
+//
+// authRule 将提供者绑定到 URL 模式
+type authRule struct {
+	pattern  string       // URL pattern // URL 模式
+	provider AuthProvider // Credential provider // 凭据提供者
+}
+
+// NewAuthRegistry creates a blank AuthRegistry
+//
+// NewAuthRegistry 创建空的 AuthRegistry
+func NewAuthRegistry() *AuthRegistry {
+	return &AuthRegistry{}
+}
+
+// Add binds a provider to a URL pattern and returns updated AuthRegistry
+// Pattern matches the host (*.example.com), or host/path with globs per segment (github.com/org/*),
+// a host/path pattern also matches every repo below it (github.com/org), blank or * matches all
+//
+// Add 将提供者绑定到 URL 模式并返回更新的 AuthRegistry
+// 模式匹配主机（*.example.com），或按段使用通配符匹配 host/path（github.com/org/*），
+// host/path 模式也会匹配其下的所有仓库（github.com/org），为空或 * 时匹配全部
+func (r *AuthRegistry) Add(pattern string, provider AuthProvider) *AuthRegistry {
+	r.rules = append(r.rules, &authRule{pattern: pattern, provider: provider})
+	return r
+}
+
+// AuthMethod returns the credentials of the first matching rule giving some
+// Returns nil auth when no rule gives credentials
+//
+// AuthMethod 返回第一个匹配且给出凭据的规则的凭据
+// 没有规则给出凭据时返回 nil 认证
+func (r *AuthRegistry) AuthMethod(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+	for _, rule := range r.rules {
+		if !matchAuthPattern(rule.pattern, remoteURL) {
+			continue
+		}
+		auth, err := rule.provider.AuthMethod(remoteURL)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if auth != nil {
+			return auth, nil
+		}
+	}
+	return nil, nil
+}
+
+// Resolve parses the remote URL and returns the credentials picked by the rules
+//
+// Resolve 解析远程 URL 并返回规则选出的凭据
+func (r *AuthRegistry) Resolve(remoteURL string) (transport.AuthMethod, error) {
+	parsed, err := ParseRemoteURL(remoteURL)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	auth, err := r.AuthMethod(parsed)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return auth, nil
+}
+
+// matchAuthPattern checks if the pattern matches the host or host/path of the URL
+//
+// matchAuthPattern 检查模式是否匹配 URL 的主机或 host/path
+func matchAuthPattern(pattern string, remoteURL *RemoteURL) bool {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	host := strings.ToLower(remoteURL.Host)
+	if matched, _ := path.Match(strings.ToLower(pattern), host); matched {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		return false
+	}
+	patternParts := strings.Split(pattern, "/")
+	urlParts := strings.Split(host+"/"+strings.TrimSuffix(strings.Trim(remoteURL.Path, "/"), ".git"), "/")
+	if len(urlParts) < len(patternParts) {
+		return false
+	}
+	for idx, part := range patternParts {
+		if idx == 0 {
+			part = strings.ToLower(part)
+		}
+		if matched, _ := path.Match(part, urlParts[idx]); !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package gogitassist
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/yyle88/erero"
+)
+
+// NewCredentialHelperAuth returns a provider asking a git credential helper, with http and https URLs
+// Command blank means git credential fill, which runs the helpers configured in git config,
+// a helper program can be called direct with its get action, like git-credential-store get
+// The command gets protocol, host and path on stdin and answers username and password on stdout,
+// terminal prompts are disabled, so a missing credential gives no credentials instead of hanging
+// Values holding a newline, carriage return or NUL are rejected as git does, so a crafted URL
+// cannot smuggle extra attributes like host= into the request
+//
+// NewCredentialHelperAuth 返回询问 git 凭据助手的提供者，用于 http 和 https URL
+// command 为空表示 git credential fill，它会运行 git 配置中的凭据助手，
+// 也可以直接调用助手程序的 get 动作，如 git-credential-store get
+// 命令从 stdin 读取 protocol、host 和 path，并在 stdout 回答 username 和 password，
+// 终端提示被禁用，因此缺少凭据时不提供凭据而不是挂起
+// 与 git 一样，拒绝包含换行、回车或 NUL 的值，使构造的 URL
+// 无法在请求中夹带 host= 等额外属性
+func NewCredentialHelperAuth(command ...string) AuthProvider {
+	if len(command) == 0 {
+		command = []string{"git", "credential", "fill"}
+	}
+	return AuthProviderFunc(func(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+		if !isHTTPScheme(remoteURL) {
+			return nil, nil
+		}
+		values, err := runCredentialHelper(command, remoteURL)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if values["password"] == "" {
+			return nil, nil
+		}
+		username := values["username"]
+		if username == "" {
+			username = remoteURL.User
+		}
+		return &http.BasicAuth{Username: username, Password: values["password"]}, nil
+	})
+}
+
+// runCredentialHelper runs the command with the credential description of the URL
+// Returns the key=value attributes it prints, nil when git reports that it would have to prompt
+//
+// runCredentialHelper 使用 URL 的凭据描述运行命令
+// 返回其输出的 key=value 属性，git 报告需要提示输入时返回 nil
+func runCredentialHelper(command []string, remoteURL *RemoteURL) (map[string]string, error) {
+	host := remoteURL.Host
+	if remoteURL.Port != 0 {
+		host += ":" + strconv.Itoa(remoteURL.Port)
+	}
+	var attributes = [][2]string{
+		{"protocol", remoteURL.Scheme},
+		{"host", host},
+		{"path", remoteURL.Path},
+	}
+	if remoteURL.User != "" {
+		attributes = append(attributes, [2]string{"username", remoteURL.User})
+	}
+	var input bytes.Buffer
+	for _, attribute := range attributes {
+		if strings.ContainsAny(attribute[1], "\n\r\x00") {
+			return nil, erero.Errorf("credential %s %q holds a newline, carriage return or NUL", attribute[0], attribute[1])
+		}
+		input.WriteString(attribute[0] + "=" + attribute[1] + "\n")
+	}
+	input.WriteString("\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stdin = &input
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// git credential fill fails this way when no helper knows the URL and prompts are disabled
+		// 没有助手知道该 URL 且禁用了提示时，git credential fill 以这种方式失败
+		var exitError *exec.ExitError
+		if errors.As(err, &exitError) && strings.Contains(stderr.String(), "terminal prompts disabled") {
+			return nil, nil
+		}
+		return nil, erero.Wrapf(err, "credential helper %q failed: %s", strings.Join(command, " "), strings.TrimSpace(stderr.String()))
+	}
+
+	var values = map[string]string{}
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok {
+			values[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return values, nil
+}
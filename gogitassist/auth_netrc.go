@@ -0,0 +1,156 @@
+package gogitassist
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexistpath/osomitexist"
+)
+
+// netrcEntry is one machine or default entry of a netrc file
+//
+// netrcEntry 是 netrc 文件中的一个 machine 或 default 条目
+type netrcEntry struct {
+	machine  string // Host name, blank with the default entry // 主机名，default 条目为空
+	login    string // Login name // 登录名
+	password string // Password // 密码
+}
+
+// NewNetrcAuth returns a provider reading credentials from a netrc file, with http and https URLs
+// Path blank means $NETRC, else ~/.netrc (~/_netrc on Windows), a missing file gives no credentials
+// The file is read on each call, so edits apply without rebuilding the provider
+//
+// NewNetrcAuth 返回从 netrc 文件读取凭据的提供者，用于 http 和 https URL
+// path 为空表示 $NETRC，否则为 ~/.netrc（Windows 上为 ~/_netrc），文件不存在时不提供凭据
+// 每次调用都会读取文件，因此修改无需重建提供者即可生效
+func NewNetrcAuth(path string) AuthProvider {
+	return AuthProviderFunc(func(remoteURL *RemoteURL) (transport.AuthMethod, error) {
+		if !isHTTPScheme(remoteURL) {
+			return nil, nil
+		}
+		netrcPath, err := resolveNetrcPath(path)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if !osomitexist.IsFile(netrcPath) {
+			return nil, nil
+		}
+		entries, err := parseNetrcFile(netrcPath)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		entry := matchNetrcEntry(entries, remoteURL.Host)
+		if entry == nil || entry.password == "" {
+			return nil, nil
+		}
+		return &http.BasicAuth{Username: entry.login, Password: entry.password}, nil
+	})
+}
+
+// resolveNetrcPath returns the given path, else $NETRC, else the netrc file in the home DIR
+//
+// resolveNetrcPath 返回给定路径，否则为 $NETRC，再否则为主目录中的 netrc 文件
+func resolveNetrcPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if path := os.Getenv("NETRC"); path != "" {
+		return path, nil
+	}
+	homeDIR, err := os.UserHomeDir()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(homeDIR, "_netrc"), nil
+	}
+	return filepath.Join(homeDIR, ".netrc"), nil
+}
+
+// parseNetrcFile parses machine and default entries, skipping macdef bodies and # comments
+//
+// parseNetrcFile 解析 machine 和 default 条目，跳过 macdef 宏体和 # 注释
+func parseNetrcFile(path string) ([]*netrcEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var entries []*netrcEntry
+	var current *netrcEntry
+	var inMacro bool
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if inMacro {
+			// A macdef body ends at the first blank line
+			// macdef 宏体在第一个空行处结束
+			inMacro = line != ""
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for idx := 0; idx < len(fields); idx++ {
+			var value string
+			if idx+1 < len(fields) {
+				value = fields[idx+1]
+			}
+			switch fields[idx] {
+			case "machine":
+				current = &netrcEntry{machine: strings.ToLower(value)}
+				entries = append(entries, current)
+				idx++
+			case "default":
+				current = &netrcEntry{}
+				entries = append(entries, current)
+			case "login":
+				if current != nil {
+					current.login = value
+				}
+				idx++
+			case "password":
+				if current != nil {
+					current.password = value
+				}
+				idx++
+			case "account":
+				idx++
+			case "macdef":
+				inMacro = true
+				idx = len(fields)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return entries, nil
+}
+
+// matchNetrcEntry returns the entry of the host, else the default entry, else nil
+//
+// matchNetrcEntry 返回主机对应的条目，否则返回 default 条目，再否则返回 nil
+func matchNetrcEntry(entries []*netrcEntry, host string) *netrcEntry {
+	host = strings.ToLower(host)
+	var fallback *netrcEntry
+	for _, entry := range entries {
+		if entry.machine == host {
+			return entry
+		}
+		if entry.machine == "" && fallback == nil {
+			fallback = entry
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,214 @@
+package gogitassist_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+	"golang.org/x/crypto/ssh"
+)
+
+// TestAuthRegistry verifies rules are matched by URL pattern and tried in order
+// Providers not fitting the scheme or lacking credentials pass the turn to the next rule
+//
+// TestAuthRegistry 验证规则按 URL 模式匹配并按顺序尝试
+// 不适用该协议或缺少凭据的提供者会让给下一条规则
+func TestAuthRegistry(t *testing.T) {
+	registry := gogitassist.NewAuthRegistry().
+		Add("github.com/go-xlan", gogitassist.NewTokenAuth("xlan-token")).
+		Add("github.com/*/private-*", gogitassist.NewBasicAuth("account", "secret")).
+		Add("*.corp.example.com", gogitassist.NewBasicAuth("corp", "corp-secret")).
+		Add("*", gogitassist.NewTokenAuth("fallback-token"))
+
+	for remoteURL, expected := range map[string]transport.AuthMethod{
+		"https://github.com/go-xlan/gogit.git":       &http.BasicAuth{Username: "x-access-token", Password: "xlan-token"},
+		"https://github.com/example/private-repo":    &http.BasicAuth{Username: "account", Password: "secret"},
+		"https://git.corp.example.com/team/repo.git": &http.BasicAuth{Username: "corp", Password: "corp-secret"},
+		"https://gitlab.com/example/repo.git":        &http.BasicAuth{Username: "x-access-token", Password: "fallback-token"},
+		"https://github.com/go-xlan-fork/gogit.git":  &http.BasicAuth{Username: "x-access-token", Password: "fallback-token"},
+		"git@github.com:go-xlan/gogit.git":           nil,
+		"/srv/git/project.git":                       nil,
+	} {
+		auth, err := registry.Resolve(remoteURL)
+		require.NoError(t, err, remoteURL)
+		if expected == nil {
+			require.Nil(t, auth, remoteURL)
+			continue
+		}
+		require.Equal(t, expected, auth, remoteURL)
+	}
+}
+
+// TestAuthRegistry_SSHAgentMissing verifies a missing SSH agent passes the turn to the next rule
+//
+// TestAuthRegistry_SSHAgentMissing 验证缺少 SSH agent 时让给下一条规则
+func TestAuthRegistry_SSHAgentMissing(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows uses pageant instead of SSH_AUTH_SOCK")
+	}
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-auth-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	fallback := &gitssh.Password{User: "git", Password: "fallback"}
+	registry := gogitassist.NewAuthRegistry().
+		Add("*", gogitassist.NewSSHAgentAuth("")).
+		Add("*", gogitassist.AuthProviderFunc(func(remoteURL *gogitassist.RemoteURL) (transport.AuthMethod, error) {
+			return fallback, nil
+		}))
+
+	for _, socketPath := range []string{"", filepath.Join(tempDIR, "missing-agent.sock")} {
+		t.Setenv("SSH_AUTH_SOCK", socketPath)
+		auth, err := registry.Resolve("git@github.com:go-xlan/gogit.git")
+		require.NoError(t, err, socketPath)
+		require.Same(t, fallback, auth)
+	}
+}
+
+// TestNewSSHKeyAuth verifies loading a passphrase protected key with the URL user
+//
+// TestNewSSHKeyAuth 验证使用 URL 中的用户加载受口令保护的私钥
+func TestNewSSHKeyAuth(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-auth-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	_, privateKey := rese.V2(ed25519.GenerateKey(rand.Reader))
+	block := rese.P1(ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", []byte("passphrase")))
+	keyPath := filepath.Join(tempDIR, "id_ed25519")
+	must.Done(os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600))
+
+	remoteURL := rese.P1(gogitassist.ParseRemoteURL("deploy@example.com:team/repo.git"))
+	auth, err := gogitassist.NewSSHKeyAuth("", keyPath, "passphrase").AuthMethod(remoteURL)
+	require.NoError(t, err)
+	require.Equal(t, "deploy", auth.(*gitssh.PublicKeys).User)
+
+	_, err = gogitassist.NewSSHKeyAuth("", keyPath, "wrong").AuthMethod(remoteURL)
+	require.Error(t, err)
+
+	httpsURL := rese.P1(gogitassist.ParseRemoteURL("https://example.com/team/repo.git"))
+	auth, err = gogitassist.NewSSHKeyAuth("", keyPath, "passphrase").AuthMethod(httpsURL)
+	require.NoError(t, err)
+	require.Nil(t, auth)
+}
+
+// TestNewNetrcAuth verifies machine entries, the default entry and macdef bodies being skipped
+//
+// TestNewNetrcAuth 验证 machine 条目、default 条目以及跳过 macdef 宏体
+func TestNewNetrcAuth(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-auth-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	netrcPath := filepath.Join(tempDIR, ".netrc")
+	must.Done(os.WriteFile(netrcPath, []byte(`# credentials
+machine github.com login octocat password gh-secret
+macdef init
+machine fake.example.com login fake password fake
+
+machine GitLab.com
+  login gitlab-account
+  password gl-secret
+default login anonymous password guest
+`), 0600))
+
+	provider := gogitassist.NewNetrcAuth(netrcPath)
+	for remoteURL, expected := range map[string]*http.BasicAuth{
+		"https://github.com/go-xlan/gogit.git":   {Username: "octocat", Password: "gh-secret"},
+		"https://gitlab.com/group/project.git":   {Username: "gitlab-account", Password: "gl-secret"},
+		"https://fake.example.com/team/repo.git": {Username: "anonymous", Password: "guest"},
+	} {
+		auth, err := provider.AuthMethod(rese.P1(gogitassist.ParseRemoteURL(remoteURL)))
+		require.NoError(t, err, remoteURL)
+		require.Equal(t, expected, auth, remoteURL)
+	}
+
+	auth, err := gogitassist.NewNetrcAuth(filepath.Join(tempDIR, "missing")).AuthMethod(rese.P1(gogitassist.ParseRemoteURL("https://github.com/go-xlan/gogit.git")))
+	require.NoError(t, err)
+	require.Nil(t, auth)
+}
+
+// TestNewCredentialHelperAuth verifies talking to a stand-in helper script with the fill protocol
+//
+// TestNewCredentialHelperAuth 验证使用 fill 协议与替身助手脚本交互
+func TestNewCredentialHelperAuth(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script needs a POSIX shell")
+	}
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-auth-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	// The helper answers hosts it knows and echoes the request, so the test sees what was sent
+	// 助手回答已知的主机并回显请求，使测试能看到发送的内容
+	helperPath := filepath.Join(tempDIR, "git-credential-stand-in")
+	must.Done(os.WriteFile(helperPath, []byte(`#!/bin/sh
+request=$(cat)
+echo "$request" > "$(dirname "$0")/request.txt"
+case "$request" in
+*host=git.example.com*) echo "username=helper-account"; echo "password=helper-secret" ;;
+esac
+`), 0755))
+
+	provider := gogitassist.NewCredentialHelperAuth(helperPath, "get")
+	auth, err := provider.AuthMethod(rese.P1(gogitassist.ParseRemoteURL("https://git.example.com:8443/team/repo.git")))
+	require.NoError(t, err)
+	require.Equal(t, &http.BasicAuth{Username: "helper-account", Password: "helper-secret"}, auth)
+	request := string(rese.V1(os.ReadFile(filepath.Join(tempDIR, "request.txt"))))
+	require.Contains(t, request, "protocol=https\n")
+	require.Contains(t, request, "host=git.example.com:8443\n")
+	require.Contains(t, request, "path=team/repo.git\n")
+
+	auth, err = provider.AuthMethod(rese.P1(gogitassist.ParseRemoteURL("https://other.example.com/team/repo.git")))
+	require.NoError(t, err)
+	require.Nil(t, auth)
+
+	_, err = gogitassist.NewCredentialHelperAuth(filepath.Join(tempDIR, "missing")).AuthMethod(rese.P1(gogitassist.ParseRemoteURL("https://git.example.com/team/repo.git")))
+	require.Error(t, err)
+
+	// A newline decoded from the path must not reach the helper as an extra host= line
+	// 从路径解码出的换行不能以额外的 host= 行传给助手
+	must.Done(os.Remove(filepath.Join(tempDIR, "request.txt")))
+	_, err = provider.AuthMethod(rese.P1(gogitassist.ParseRemoteURL("https://evil.example.com/x%0ahost=git.example.com%0a/repo.git")))
+	require.Error(t, err)
+	require.NoFileExists(t, filepath.Join(tempDIR, "request.txt"))
+}
+
+// TestAuthRegistry_CredentialHelperEmpty verifies a helper with nothing stored passes the turn to the next rule
+// git credential fill exits 128 about disabled terminal prompts then, which means no credentials
+//
+// TestAuthRegistry_CredentialHelperEmpty 验证没有存储凭据的助手让给下一条规则
+// 此时 git credential fill 因终端提示被禁用以 128 退出，这表示没有凭据
+func TestAuthRegistry_CredentialHelperEmpty(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("helper script needs a POSIX shell")
+	}
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-auth-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	helperPath := filepath.Join(tempDIR, "git-credential-empty")
+	must.Done(os.WriteFile(helperPath, []byte(`#!/bin/sh
+cat > /dev/null
+echo "fatal: could not read Username for 'https://git.example.com': terminal prompts disabled" >&2
+exit 128
+`), 0755))
+
+	registry := gogitassist.NewAuthRegistry().
+		Add("*", gogitassist.NewCredentialHelperAuth(helperPath)).
+		Add("*", gogitassist.NewBasicAuth("account", "secret"))
+	auth, err := registry.Resolve("https://git.example.com/team/repo.git")
+	require.NoError(t, err)
+	require.Equal(t, &http.BasicAuth{Username: "account", Password: "secret"}, auth)
+}
@@ -0,0 +1,246 @@
+package gogitassist
+
+import (
+	"io"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/yyle88/erero"
+)
+
+// CloneOptions configures how Clone fetches and checks out a remote repo
+// Supports fluent configuration pattern for convenient setup
+//
+// CloneOptions 配置 Clone 如何获取和检出远程仓库
+// 支持流畅配置模式以便于设置
+type CloneOptions struct {
+	remoteName    string         // Remote name, "origin" when blank // 远程名称，为空时为 "origin"
+	depth         int            // Shallow clone depth, 0 means full history // 浅克隆深度，0 表示完整历史
+	singleBranch  bool           // Fetch just the chosen reference // 仅获取所选引用
+	branch        string         // Branch to check out, remote HEAD when blank // 要检出的分支，为空时为远程 HEAD
+	tag           string         // Tag to check out as detached HEAD // 以分离 HEAD 检出的标签
+	commit        string         // Commit hash to check out as detached HEAD // 以分离 HEAD 检出的提交哈希
+	sparseDIRs    []string       // DIRs kept in a sparse checkout // 稀疏检出中保留的目录
+	progress      io.Writer      // Receives progress text sent by the server // 接收服务器发送的进度文本
+	ignoreOptions *IgnoreOptions // Ignore layers applied to the worktree // 应用到工作树的忽略层
+	auth          AuthProvider   // Credentials of the url, none when nil // url 的凭据，为 nil 时不使用
+	hostKeyPolicy *HostKeyPolicy // SSH host key policy, go-git default when nil // SSH 主机密钥策略，为 nil 时使用 go-git 默认值
+}
+
+// NewCloneOptions creates options for a full clone of the remote HEAD with every ignore layer
+//
+// NewCloneOptions 创建完整克隆远程 HEAD 并启用所有忽略层的选项
+func NewCloneOptions() *CloneOptions {
+	return &CloneOptions{
+		ignoreOptions: NewIgnoreOptions(),
+	}
+}
+
+// RemoteName sets the name given to the cloned remote and returns updated CloneOptions
+//
+// RemoteName 设置克隆远程的名称并返回更新的 CloneOptions
+func (o *CloneOptions) RemoteName(remoteName string) *CloneOptions {
+	o.remoteName = remoteName
+	return o
+}
+
+// Depth sets the count of commits fetched from each tip and returns updated CloneOptions
+// Zero fetches the full history
+//
+// Depth 设置从每个末端获取的提交数量并返回更新的 CloneOptions
+// 零表示获取完整历史
+func (o *CloneOptions) Depth(depth int) *CloneOptions {
+	o.depth = depth
+	return o
+}
+
+// SingleBranch sets whether just the chosen branch or tag gets fetched and returns updated CloneOptions
+//
+// SingleBranch 设置是否仅获取所选分支或标签并返回更新的 CloneOptions
+func (o *CloneOptions) SingleBranch(singleBranch bool) *CloneOptions {
+	o.singleBranch = singleBranch
+	return o
+}
+
+// Branch sets the branch to check out and returns updated CloneOptions
+//
+// Branch 设置要检出的分支并返回更新的 CloneOptions
+func (o *CloneOptions) Branch(branch string) *CloneOptions {
+	o.branch = branch
+	return o
+}
+
+// Tag sets the tag to check out as detached HEAD and returns updated CloneOptions
+// Takes the place of Branch when both are set
+//
+// Tag 设置以分离 HEAD 检出的标签并返回更新的 CloneOptions
+// 同时设置时优先于 Branch
+func (o *CloneOptions) Tag(tag string) *CloneOptions {
+	o.tag = tag
+	return o
+}
+
+// Commit sets the commit hash to check out as detached HEAD and returns updated CloneOptions
+// The commit must be fetched, so keep it within the depth and the chosen branch
+//
+// Commit 设置以分离 HEAD 检出的提交哈希并返回更新的 CloneOptions
+// 该提交必须被获取到，因此需要位于深度和所选分支范围内
+func (o *CloneOptions) Commit(commit string) *CloneOptions {
+	o.commit = commit
+	return o
+}
+
+// SparseDIRs sets the DIRs kept in the worktree, others are left out, and returns updated CloneOptions
+//
+// SparseDIRs 设置工作树中保留的目录（其他目录不检出）并返回更新的 CloneOptions
+func (o *CloneOptions) SparseDIRs(dirs ...string) *CloneOptions {
+	o.sparseDIRs = dirs
+	return o
+}
+
+// Progress sets the writer receiving progress text sent by the server and returns updated CloneOptions
+//
+// Progress 设置接收服务器发送的进度文本的写入器并返回更新的 CloneOptions
+func (o *CloneOptions) Progress(progress io.Writer) *CloneOptions {
+	o.progress = progress
+	return o
+}
+
+// IgnoreOptions sets the ignore layers applied to the worktree and returns updated CloneOptions
+//
+// IgnoreOptions 设置应用到工作树的忽略层并返回更新的 CloneOptions
+func (o *CloneOptions) IgnoreOptions(ignoreOptions *IgnoreOptions) *CloneOptions {
+	o.ignoreOptions = ignoreOptions
+	return o
+}
+
+// Auth sets the provider giving credentials of the url and returns updated CloneOptions
+//
+// Auth 设置为 url 提供凭据的提供者并返回更新的 CloneOptions
+func (o *CloneOptions) Auth(auth AuthProvider) *CloneOptions {
+	o.auth = auth
+	return o
+}
+
+// HostKeyPolicy sets how SSH host keys of the url get verified and returns updated CloneOptions
+//
+// HostKeyPolicy 设置 url 的 SSH 主机密钥校验方式并返回更新的 CloneOptions
+func (o *CloneOptions) HostKeyPolicy(hostKeyPolicy *HostKeyPolicy) *CloneOptions {
+	o.hostKeyPolicy = hostKeyPolicy
+	return o
+}
+
+// Clone clones the repo at url into path and returns repo and worktree ready for gogit.NewClient
+// The worktree gets ignore patterns applied like NewRepoTreeWithIgnore
+// Supports local paths and file:// URLs besides network transports
+// Nil options and nil ignore options act as defaults, a failed checkout leaves no partial clone behind
+//
+// Clone 将 url 处的仓库克隆到 path，并返回可直接用于 gogit.NewClient 的仓库和工作树
+// 工作树会像 NewRepoTreeWithIgnore 一样应用忽略模式
+// 除网络传输外还支持本地路径和 file:// URL
+// 选项和忽略选项为 nil 时使用默认值，检出失败时不会留下不完整的克隆
+func Clone(url string, path string, options *CloneOptions) (*git.Repository, *git.Worktree, error) {
+	if options == nil {
+		options = NewCloneOptions()
+	}
+	var ignoreOptions = options.ignoreOptions
+	if ignoreOptions == nil {
+		ignoreOptions = NewIgnoreOptions()
+	}
+	if options.commit != "" && !plumbing.IsHash(options.commit) {
+		return nil, nil, erero.Errorf("wrong commit hash %q", options.commit)
+	}
+
+	var auth transport.AuthMethod
+	if options.auth != nil || options.hostKeyPolicy != nil {
+		remoteURL, err := ParseRemoteURL(url)
+		if err != nil {
+			return nil, nil, erero.Wro(err)
+		}
+		if auth, err = ResolveAuthMethod(options.auth, options.hostKeyPolicy, remoteURL); err != nil {
+			return nil, nil, erero.Wro(err)
+		}
+	}
+	var cloneOptions = &git.CloneOptions{
+		URL:          url,
+		RemoteName:   options.remoteName,
+		Depth:        options.depth,
+		SingleBranch: options.singleBranch,
+		Progress:     options.progress,
+		Auth:         auth,
+		// Checkout runs after clone when a commit or sparse DIRs are chosen
+		// 选择了提交或稀疏目录时，在克隆之后执行检出
+		NoCheckout: options.commit != "" || len(options.sparseDIRs) > 0,
+	}
+	if options.tag != "" {
+		cloneOptions.ReferenceName = plumbing.NewTagReferenceName(options.tag)
+	} else if options.branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(options.branch)
+	}
+	// go-git cleans up a failed clone itself, the steps after it need the same
+	// go-git 会自行清理失败的克隆，其后的步骤也需要同样的清理
+	existingNames, err := readExistingNames(path)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	repo, err := git.PlainClone(path, false, cloneOptions)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+
+	repo, tree, err := prepareClone(repo, path, options, ignoreOptions, cloneOptions.NoCheckout)
+	if err != nil {
+		if cleanupErr := removeCreatedNames(path, existingNames); cleanupErr != nil {
+			return nil, nil, erero.Wrapf(err, "cleanup failed: %v", cleanupErr)
+		}
+		return nil, nil, erero.Wro(err)
+	}
+	return repo, tree, nil
+}
+
+// prepareClone runs the checkout when it was held back, then opens the worktree with ignore layers
+//
+// prepareClone 在检出被推迟时执行检出，然后打开带有忽略层的工作树
+func prepareClone(repo *git.Repository, path string, options *CloneOptions, ignoreOptions *IgnoreOptions, checkout bool) (*git.Repository, *git.Worktree, error) {
+	if checkout {
+		if err := checkoutClone(repo, options); err != nil {
+			return nil, nil, erero.Wro(err)
+		}
+	}
+	repo, tree, err := NewRepoTreeWithIgnoreOptions(path, ignoreOptions)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	return repo, tree, nil
+}
+
+// checkoutClone checks out the chosen commit, or HEAD of the clone, with the sparse DIRs
+//
+// checkoutClone 检出所选提交或克隆的 HEAD，并应用稀疏目录
+func checkoutClone(repo *git.Repository, options *CloneOptions) error {
+	tree, err := repo.Worktree()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return erero.Wro(err)
+	}
+
+	var checkoutOptions = &git.CheckoutOptions{
+		Force:                     true,
+		SparseCheckoutDirectories: options.sparseDIRs,
+	}
+	if options.commit != "" {
+		checkoutOptions.Hash = plumbing.NewHash(options.commit)
+	} else if head.Name().IsBranch() {
+		checkoutOptions.Branch = head.Name()
+	} else {
+		checkoutOptions.Hash = head.Hash()
+	}
+	if err := tree.Checkout(checkoutOptions); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
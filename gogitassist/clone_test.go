@@ -0,0 +1,125 @@
+package gogitassist_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// setupCloneSource creates a source repo with two commits on main, tag v1 on the first one
+// Returns the source path with the first and second commit hashes
+//
+// setupCloneSource 创建在 main 上有两个提交的源仓库，标签 v1 位于第一个提交
+// 返回源路径以及第一个和第二个提交的哈希
+func setupCloneSource(t *testing.T) (string, plumbing.Hash, plumbing.Hash) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-clone-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	source := filepath.Join(tempDIR, "source")
+	writeIgnoreTestFile(source, ".gitignore", "*.log\n")
+	writeIgnoreTestFile(source, "docs/guide.md", "# Guide\n")
+	writeIgnoreTestFile(source, "src/main.go", "package main\n")
+	repo := rese.P1(gogitassist.InitRepoWithOptions(source, gogitassist.NewInitOptions().
+		Branch("main").
+		UserInfo("Test Account", "test@example.com").
+		InitialCommit("First commit")))
+	first := rese.P1(repo.Head()).Hash()
+	rese.P1(repo.CreateTag("v1", first, nil))
+
+	writeIgnoreTestFile(source, "src/main.go", "package main\n\nfunc main() {}\n")
+	second := rese.V1(gogitassist.Commit(repo, "Second commit", "Test Account", "test@example.com"))
+	return source, first, second
+}
+
+// TestClone verifies a shallow single-branch clone with progress and ignore patterns applied
+//
+// TestClone 验证带进度和已应用忽略模式的浅层单分支克隆
+func TestClone(t *testing.T) {
+	source, _, second := setupCloneSource(t)
+	target := filepath.Join(filepath.Dir(source), "shallow")
+
+	var progress bytes.Buffer
+	repo, tree, err := gogitassist.Clone("file://"+source, target, gogitassist.NewCloneOptions().
+		Branch("main").
+		Depth(1).
+		SingleBranch(true).
+		Progress(&progress))
+	require.NoError(t, err)
+	t.Log(progress.String())
+
+	head := rese.P1(repo.Head())
+	require.Equal(t, plumbing.NewBranchReferenceName("main"), head.Name())
+	require.Equal(t, second, head.Hash())
+
+	shallows, err := repo.Storer.Shallow()
+	require.NoError(t, err)
+	require.Contains(t, shallows, second)
+
+	writeIgnoreTestFile(target, "debug.log", "log\n")
+	status := rese.V1(tree.Status())
+	require.True(t, status.IsClean())
+}
+
+// TestClone_TagCommitSparse verifies checking out a tag, a commit, and a sparse set of DIRs
+//
+// TestClone_TagCommitSparse 验证检出标签、提交以及稀疏目录集合
+func TestClone_TagCommitSparse(t *testing.T) {
+	source, first, second := setupCloneSource(t)
+	root := filepath.Dir(source)
+
+	repo, _, err := gogitassist.Clone(source, filepath.Join(root, "tag"), gogitassist.NewCloneOptions().Tag("v1"))
+	require.NoError(t, err)
+	head := rese.P1(repo.Head())
+	require.Equal(t, plumbing.HEAD, head.Name())
+	require.Equal(t, first, head.Hash())
+	require.Equal(t, "package main\n", string(rese.V1(os.ReadFile(filepath.Join(root, "tag", "src/main.go")))))
+
+	repo, _, err = gogitassist.Clone(source, filepath.Join(root, "commit"), gogitassist.NewCloneOptions().Commit(first.String()))
+	require.NoError(t, err)
+	require.Equal(t, first, rese.P1(repo.Head()).Hash())
+
+	repo, _, err = gogitassist.Clone(source, filepath.Join(root, "sparse"), gogitassist.NewCloneOptions().SparseDIRs("src"))
+	require.NoError(t, err)
+	head = rese.P1(repo.Head())
+	require.Equal(t, plumbing.NewBranchReferenceName("main"), head.Name())
+	require.Equal(t, second, head.Hash())
+	require.FileExists(t, filepath.Join(root, "sparse", "src/main.go"))
+	require.NoFileExists(t, filepath.Join(root, "sparse", "docs/guide.md"))
+
+	_, _, err = gogitassist.Clone(source, filepath.Join(root, "wrong"), gogitassist.NewCloneOptions().Commit("not-a-hash"))
+	require.Error(t, err)
+}
+
+// TestClone_Defaults verifies nil options act as defaults and a failed checkout leaves no partial clone
+//
+// TestClone_Defaults 验证 nil 选项按默认值处理，且检出失败时不留下不完整的克隆
+func TestClone_Defaults(t *testing.T) {
+	source, _, second := setupCloneSource(t)
+	root := filepath.Dir(source)
+
+	for name, options := range map[string]*gogitassist.CloneOptions{
+		"nil":   nil,
+		"blank": {},
+	} {
+		repo, tree, err := gogitassist.Clone(source, filepath.Join(root, name), options)
+		require.NoError(t, err, name)
+		require.Equal(t, second, rese.P1(repo.Head()).Hash())
+		writeIgnoreTestFile(filepath.Join(root, name), "debug.log", "log\n")
+		require.True(t, rese.V1(tree.Status()).IsClean(), name)
+	}
+
+	// The commit is a well formed hash missing from the source, so the checkout fails
+	// 该提交是格式正确但源仓库中不存在的哈希，因此检出失败
+	missing := plumbing.ComputeHash(plumbing.BlobObject, []byte("missing"))
+	_, _, err := gogitassist.Clone(source, filepath.Join(root, "missing"), gogitassist.NewCloneOptions().Commit(missing.String()))
+	require.Error(t, err)
+	require.NoDirExists(t, filepath.Join(root, "missing"))
+}
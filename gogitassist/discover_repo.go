@@ -0,0 +1,115 @@
+package gogitassist
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/yyle88/erero"
+)
+
+// RepoLocation describes where a repo lives as seen from a path inside its worktree
+// For linked worktrees and submodules GitDIR differs from Root/.git, and CommonDIR holds the shared data
+//
+// RepoLocation 描述从工作树内某个路径看到的仓库位置
+// 对于链接工作树和子模块，GitDIR 不同于 Root/.git，CommonDIR 保存共享数据
+type RepoLocation struct {
+	Root      string // Worktree top DIR holding the .git entry // 包含 .git 条目的工作树顶层目录
+	SubPath   string // Slash-separated path of the requested DIR inside Root, blank at the top // 所请求目录在 Root 中以斜杠分隔的路径，位于顶层时为空
+	GitDIR    string // Git DIR of this worktree with HEAD and index // 此工作树的 git 目录，包含 HEAD 和索引
+	CommonDIR string // Git DIR shared by linked worktrees with objects, refs and config // 链接工作树共享的 git 目录，包含对象、引用和配置
+}
+
+// DiscoverRepo walks up from path to the enclosing worktree, like git rev-parse --show-toplevel
+// The .git entry may be a DIR or a file with "gitdir:" indirection, as in linked worktrees and submodules
+// A path to a file resolves from its DIR, the path itself need not exist
+//
+// DiscoverRepo 从路径向上查找所在的工作树，类似 git rev-parse --show-toplevel
+// .git 条目可以是目录，也可以是带 "gitdir:" 间接引用的文件，如链接工作树和子模块
+// 文件路径从其所在目录开始解析，路径本身不必存在
+func DiscoverRepo(path string) (*RepoLocation, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	start := absPath
+	if info, err := os.Stat(absPath); err == nil && !info.IsDir() {
+		start = filepath.Dir(absPath)
+	}
+
+	for dir := start; ; {
+		if _, err := os.Stat(filepath.Join(dir, git.GitDirName)); err == nil {
+			gitDIR, commonDIR, err := resolveGitDIRs(dir)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			subPath, err := filepath.Rel(dir, start)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			if subPath == "." {
+				subPath = ""
+			}
+			return &RepoLocation{
+				Root:      dir,
+				SubPath:   filepath.ToSlash(subPath),
+				GitDIR:    gitDIR,
+				CommonDIR: commonDIR,
+			}, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, erero.Errorf("no git repo contains %s", path)
+		}
+		dir = parent
+	}
+}
+
+// OpenRepo opens the repo enclosing path, which may be a subDIR or a linked worktree
+//
+// OpenRepo 打开包含路径的仓库，路径可以是子目录或链接工作树
+func OpenRepo(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return repo, nil
+}
+
+// resolveGitDIRs returns the git DIR and common DIR of the worktree at root
+// A missing .git gives root/.git for both, so readers find nothing instead of failing
+//
+// resolveGitDIRs 返回位于 root 的工作树的 git 目录和共享目录
+// .git 不存在时两者均为 root/.git，使读取方得到空结果而不是失败
+func resolveGitDIRs(root string) (string, string, error) {
+	gitDIR := filepath.Join(root, git.GitDirName)
+	info, err := os.Stat(gitDIR)
+	if err != nil || info.IsDir() {
+		return gitDIR, gitDIR, nil
+	}
+
+	data, err := os.ReadFile(gitDIR)
+	if err != nil {
+		return "", "", erero.Wro(err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	target, ok := strings.CutPrefix(strings.TrimSpace(line), "gitdir:")
+	if !ok {
+		return "", "", erero.Errorf("wrong .git file %s", gitDIR)
+	}
+	if gitDIR = strings.TrimSpace(target); !filepath.IsAbs(gitDIR) {
+		gitDIR = filepath.Join(root, gitDIR)
+	}
+
+	commonDIR := gitDIR
+	if data, err := os.ReadFile(filepath.Join(gitDIR, "commondir")); err == nil {
+		if commonDIR = strings.TrimSpace(string(data)); !filepath.IsAbs(commonDIR) {
+			commonDIR = filepath.Join(gitDIR, commonDIR)
+		}
+	}
+	return filepath.Clean(gitDIR), filepath.Clean(commonDIR), nil
+}
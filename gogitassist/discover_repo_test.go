@@ -0,0 +1,106 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// addLinkedWorktree lays out a linked worktree on a new branch, as git worktree add does
+// Returns the worktree path, populated with the files of HEAD
+//
+// addLinkedWorktree 像 git worktree add 一样在新分支上布置链接工作树
+// 返回工作树路径，其中已填充 HEAD 的文件
+func addLinkedWorktree(root string, name string, branch string) string {
+	repo := rese.P1(gogitassist.NewRepo(root))
+	head := rese.P1(repo.Head())
+	must.Done(repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), head.Hash())))
+
+	worktreePath := filepath.Join(filepath.Dir(root), name)
+	gitDIR := filepath.Join(root, ".git", "worktrees", name)
+	writeIgnoreTestFile(gitDIR, "HEAD", "ref: refs/heads/"+branch+"\n")
+	writeIgnoreTestFile(gitDIR, "commondir", "../..\n")
+	writeIgnoreTestFile(gitDIR, "gitdir", filepath.Join(worktreePath, ".git")+"\n")
+	writeIgnoreTestFile(worktreePath, ".git", "gitdir: "+gitDIR+"\n")
+
+	linked := rese.P1(gogitassist.OpenRepo(worktreePath))
+	tree := rese.P1(linked.Worktree())
+	must.Done(tree.Reset(&git.ResetOptions{Mode: git.HardReset}))
+	return worktreePath
+}
+
+// TestDiscoverRepo verifies finding the worktree root and subPath from nested paths
+//
+// TestDiscoverRepo 验证从嵌套路径查找工作树根目录和子路径
+func TestDiscoverRepo(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-discover-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	root := filepath.Join(tempDIR, "project")
+	writeIgnoreTestFile(root, "pkg/util/util.go", "package util\n")
+	rese.P1(gogitassist.InitRepo(root))
+
+	location, err := gogitassist.DiscoverRepo(filepath.Join(root, "pkg/util"))
+	require.NoError(t, err)
+	require.Equal(t, root, location.Root)
+	require.Equal(t, "pkg/util", location.SubPath)
+	require.Equal(t, filepath.Join(root, ".git"), location.GitDIR)
+	require.Equal(t, location.GitDIR, location.CommonDIR)
+
+	location, err = gogitassist.DiscoverRepo(filepath.Join(root, "pkg/util/util.go"))
+	require.NoError(t, err)
+	require.Equal(t, "pkg/util", location.SubPath)
+
+	location, err = gogitassist.DiscoverRepo(root)
+	require.NoError(t, err)
+	require.Equal(t, "", location.SubPath)
+
+	_, err = gogitassist.DiscoverRepo(tempDIR)
+	require.Error(t, err)
+}
+
+// TestOpenRepo_LinkedWorktree verifies opening a linked worktree from a subDIR
+// Should follow the gitdir indirection and read info/exclude from the shared DIR
+//
+// TestOpenRepo_LinkedWorktree 验证从子目录打开链接工作树
+// 应该跟随 gitdir 间接引用，并从共享目录读取 info/exclude
+func TestOpenRepo_LinkedWorktree(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-discover-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	root := filepath.Join(tempDIR, "project")
+	writeIgnoreTestFile(root, "src/main.go", "package main\n")
+	repo := rese.P1(gogitassist.InitRepo(root))
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+	writeIgnoreTestFile(root, ".git/info/exclude", "*.tmp\n")
+
+	worktreePath := addLinkedWorktree(root, "feature-tree", "feature")
+	require.FileExists(t, filepath.Join(worktreePath, "src/main.go"))
+
+	location, err := gogitassist.DiscoverRepo(filepath.Join(worktreePath, "src"))
+	require.NoError(t, err)
+	require.Equal(t, worktreePath, location.Root)
+	require.Equal(t, "src", location.SubPath)
+	require.Equal(t, filepath.Join(root, ".git", "worktrees", "feature-tree"), location.GitDIR)
+	require.Equal(t, filepath.Join(root, ".git"), location.CommonDIR)
+
+	linked, err := gogitassist.OpenRepo(filepath.Join(worktreePath, "src"))
+	require.NoError(t, err)
+	head := rese.P1(linked.Head())
+	require.Equal(t, plumbing.NewBranchReferenceName("feature"), head.Name())
+
+	writeIgnoreTestFile(worktreePath, "src/cache.tmp", "cache\n")
+	_, tree, err := gogitassist.NewRepoTreeWithIgnore(worktreePath)
+	require.NoError(t, err)
+	status := rese.V1(tree.Status())
+	require.True(t, status.IsClean())
+}
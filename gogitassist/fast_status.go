@@ -0,0 +1,327 @@
+package gogitassist
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	mindex "github.com/go-git/go-git/v5/utils/merkletrie/index"
+	"github.com/yyle88/erero"
+)
+
+// FastStatusOptions configures FastStatus
+// Supports fluent configuration pattern for convenient setup
+//
+// FastStatusOptions 配置 FastStatus
+// 支持流畅配置模式以便于设置
+type FastStatusOptions struct {
+	workers   int  // Max count of concurrent hashing workers // 最大并发哈希工作协程数
+	trustStat bool // Skip files whose stat data matches the index // 跳过 stat 数据与索引一致的文件
+}
+
+// NewFastStatusOptions creates options trusting index stat data and hashing with GOMAXPROCS workers
+//
+// NewFastStatusOptions 创建信任索引 stat 数据并使用 GOMAXPROCS 个工作协程计算哈希的选项
+func NewFastStatusOptions() *FastStatusOptions {
+	return &FastStatusOptions{
+		workers:   runtime.GOMAXPROCS(0),
+		trustStat: true,
+	}
+}
+
+// Workers sets the max count of concurrent hashing workers and returns updated FastStatusOptions
+// Values below 1 are treated as 1
+//
+// Workers 设置最大并发哈希工作协程数并返回更新的 FastStatusOptions
+// 小于 1 的值按 1 处理
+func (o *FastStatusOptions) Workers(workers int) *FastStatusOptions {
+	o.workers = max(workers, 1)
+	return o
+}
+
+// TrustStat sets whether files with size, mtime and inode matching the index skip hashing
+// Returns updated FastStatusOptions, disabling it hashes every tracked file
+//
+// TrustStat 设置大小、修改时间和 inode 与索引一致的文件是否跳过哈希计算
+// 返回更新的 FastStatusOptions，禁用后会对每个已跟踪文件计算哈希
+func (o *FastStatusOptions) TrustStat(trustStat bool) *FastStatusOptions {
+	o.trustStat = trustStat
+	return o
+}
+
+// FastStatus returns the worktree status like worktree Status, built for large repos
+// Like git status it trusts the stat data in the index, so unchanged files are not read
+// Files whose stat data differs, or is racy (not older than the index), are hashed in parallel
+// Submodules are compared by their checked out commit, as worktree Status does
+// Like worktree Status it does not read core.fileMode, so an executable bit change counts as a modification
+// The index is not refreshed
+//
+// FastStatus 返回与工作树 Status 相同的状态，专为大型仓库构建
+// 与 git status 一样信任索引中的 stat 数据，因此不会读取未变化的文件
+// stat 数据不同或处于竞态（不早于索引）的文件会被并行计算哈希
+// 与工作树 Status 一样，子模块按其检出的提交进行比较
+// 与工作树 Status 一样不读取 core.fileMode，因此可执行位的变化算作修改
+// 不会刷新索引
+func FastStatus(repo *git.Repository, tree *git.Worktree, options *FastStatusOptions) (git.Status, error) {
+	root := tree.Filesystem.Root()
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	headNoder, err := headTreeNoder(repo, "")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	left, err := merkletrie.DiffTree(headNoder, mindex.NewRootNode(idx), statusHashEquals)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var status = git.Status{}
+	if err := applyStagingChanges(status, "", left); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	rules, err := LoadProjectIgnoreRules(root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	submodules, err := submoduleHashes(tree, "")
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	scan := &worktreeScan{
+		root:       root,
+		entries:    map[string]*index.Entry{},
+		trackDIRs:  map[string]bool{},
+		seen:       map[string]bool{},
+		matcher:    gitignore.NewMatcher(append(IgnoreRulePatterns(rules), tree.Excludes...)),
+		submodules: submodules,
+		options:    options,
+	}
+	for _, entry := range idx.Entries {
+		scan.entries[entry.Name] = entry
+		for dir := path.Dir(entry.Name); dir != "."; dir = path.Dir(dir) {
+			scan.trackDIRs[dir] = true
+		}
+	}
+	if scan.indexTime, err = readIndexTime(root); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := filepath.WalkDir(root, scan.visit); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	modified, err := scan.hashCandidates()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, name := range modified {
+		applyWorktreeChange(status, name, merkletrie.Modify)
+	}
+	for _, name := range scan.untracked {
+		applyWorktreeChange(status, name, merkletrie.Insert)
+	}
+	for _, entry := range idx.Entries {
+		if !scan.seen[entry.Name] {
+			applyWorktreeChange(status, entry.Name, merkletrie.Delete)
+		}
+	}
+	return status, nil
+}
+
+// worktreeScan holds the state of one FastStatus walk over the worktree
+//
+// worktreeScan 保存一次 FastStatus 遍历工作树的状态
+type worktreeScan struct {
+	root       string                   // Worktree root path // 工作树根路径
+	entries    map[string]*index.Entry  // Index entries by name // 按名称索引的条目
+	trackDIRs  map[string]bool          // DIRs holding tracked files // 包含已跟踪文件的目录
+	seen       map[string]bool          // Index entries found in the worktree // 在工作树中找到的索引条目
+	matcher    gitignore.Matcher        // Ignore matcher of untracked paths // 未跟踪路径的忽略匹配器
+	submodules map[string]plumbing.Hash // Checked out commits of submodules // 子模块检出的提交
+	indexTime  time.Time                // Modification time of the index file // 索引文件的修改时间
+	options    *FastStatusOptions       // Scan options // 扫描选项
+	candidates []string                 // Tracked files needing a hash // 需要计算哈希的已跟踪文件
+	modified   []string                 // Tracked files changed by mode // 因文件模式而变化的已跟踪文件
+	untracked  []string                 // Untracked files not ignored // 未被忽略的未跟踪文件
+}
+
+// visit sorts one worktree path into unchanged, candidate, modified or untracked
+// Skips .git and ignored DIRs holding no tracked files
+//
+// visit 将一个工作树路径归类为未变化、候选、已修改或未跟踪
+// 跳过 .git 以及不包含已跟踪文件的被忽略目录
+func (s *worktreeScan) visit(absPath string, entry fs.DirEntry, err error) error {
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if absPath == s.root {
+		return nil
+	}
+	relativePath, err := filepath.Rel(s.root, absPath)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	name := filepath.ToSlash(relativePath)
+
+	if entry.IsDir() {
+		if entry.Name() == git.GitDirName {
+			return filepath.SkipDir
+		}
+		if indexEntry, ok := s.entries[name]; ok && indexEntry.Mode == filemode.Submodule {
+			s.seen[name] = true
+			if hash, ok := s.submodules[name]; ok && hash != indexEntry.Hash {
+				s.modified = append(s.modified, name)
+			}
+			return filepath.SkipDir
+		}
+		if !s.trackDIRs[name] && s.matcher.Match(strings.Split(name, "/"), true) {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+	if !entry.Type().IsRegular() && entry.Type()&fs.ModeSymlink == 0 {
+		return nil
+	}
+
+	indexEntry, ok := s.entries[name]
+	if !ok {
+		if !s.matcher.Match(strings.Split(name, "/"), false) {
+			s.untracked = append(s.untracked, name)
+		}
+		return nil
+	}
+	s.seen[name] = true
+	info, err := entry.Info()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if mode, err := filemode.NewFromOSFileMode(info.Mode()); err != nil || mode != indexEntry.Mode {
+		s.modified = append(s.modified, name)
+		return nil
+	}
+	if s.options.trustStat && s.statMatches(indexEntry, info) {
+		return nil
+	}
+	s.candidates = append(s.candidates, name)
+	return nil
+}
+
+// statMatches checks if size, mtime and inode match the index and the mtime is not racy
+// A file changed in the same instant the index was written could keep its stat data, so it is hashed
+//
+// statMatches 检查大小、修改时间和 inode 是否与索引一致，且修改时间不处于竞态
+// 在写入索引的同一时刻被修改的文件可能保持相同的 stat 数据，因此需要计算哈希
+func (s *worktreeScan) statMatches(indexEntry *index.Entry, info os.FileInfo) bool {
+	if indexEntry.Size != uint32(info.Size()) || !indexEntry.ModifiedAt.Equal(info.ModTime()) {
+		return false
+	}
+	if inode := fileInode(info); indexEntry.Inode != 0 && inode != 0 && indexEntry.Inode != inode {
+		return false
+	}
+	return !s.indexTime.IsZero() && info.ModTime().Before(s.indexTime)
+}
+
+// hashCandidates hashes candidate files in parallel and returns those differing from the index
+// Results keep the walk order, so runs are reproducible
+//
+// hashCandidates 并行计算候选文件的哈希，并返回与索引不同的文件
+// 结果保持遍历顺序，使多次运行结果可复现
+func (s *worktreeScan) hashCandidates() ([]string, error) {
+	var changed = make([]bool, len(s.candidates))
+	var failures = make([]error, len(s.candidates))
+	var indexes = make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.options.workers, max(len(s.candidates), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				name := s.candidates[idx]
+				hash, err := hashWorktreeFile(filepath.Join(s.root, filepath.FromSlash(name)))
+				if err != nil {
+					failures[idx] = err
+					continue
+				}
+				changed[idx] = hash != s.entries[name].Hash
+			}
+		}()
+	}
+	for idx := range s.candidates {
+		indexes <- idx
+	}
+	close(indexes)
+	wg.Wait()
+
+	var modified = s.modified
+	for idx, name := range s.candidates {
+		if failures[idx] != nil {
+			return nil, erero.Wro(failures[idx])
+		}
+		if changed[idx] {
+			modified = append(modified, name)
+		}
+	}
+	return modified, nil
+}
+
+// hashWorktreeFile computes the blob hash of a file, or of the link target of a symlink
+//
+// hashWorktreeFile 计算文件的 blob 哈希，符号链接则计算其链接目标的哈希
+func hashWorktreeFile(absPath string) (plumbing.Hash, error) {
+	info, err := os.Lstat(absPath)
+	if err != nil {
+		return plumbing.ZeroHash, erero.Wro(err)
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(absPath)
+		if err != nil {
+			return plumbing.ZeroHash, erero.Wro(err)
+		}
+		return plumbing.ComputeHash(plumbing.BlobObject, []byte(filepath.ToSlash(target))), nil
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return plumbing.ZeroHash, erero.Wro(err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	hasher := plumbing.NewHasher(plumbing.BlobObject, info.Size())
+	if _, err := io.Copy(hasher, file); err != nil {
+		return plumbing.ZeroHash, erero.Wro(err)
+	}
+	return hasher.Sum(), nil
+}
+
+// readIndexTime returns the modification time of the index file, zero when missing
+//
+// readIndexTime 返回索引文件的修改时间，文件不存在时为零值
+func readIndexTime(root string) (time.Time, error) {
+	gitDIR, _, err := resolveGitDIRs(root)
+	if err != nil {
+		return time.Time{}, erero.Wro(err)
+	}
+	info, err := os.Stat(filepath.Join(gitDIR, "index"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, erero.Wro(err)
+	}
+	return info.ModTime(), nil
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package gogitassist
+
+import "os"
+
+// fileInode returns zero, as inode numbers are not available on this platform
+//
+// fileInode 返回零，因为此平台无法获取 inode 编号
+func fileInode(info os.FileInfo) uint32 {
+	return 0
+}
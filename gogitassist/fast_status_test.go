@@ -0,0 +1,149 @@
+package gogitassist_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// setupFastStatusRepo creates a committed repo holding count files spread over DIRs
+// Returns the repo path together with repo and worktree configured with ignore patterns
+//
+// setupFastStatusRepo 创建一个已提交的仓库，其中的 count 个文件分布在多个目录中
+// 返回仓库路径以及配置了忽略模式的仓库和工作树
+func setupFastStatusRepo(tb testing.TB, count int) (string, *git.Repository, *git.Worktree) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-fast-status-test-*"))
+	tb.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, ".gitignore", "*.log\nbuild/\n")
+	for idx := range count {
+		writeIgnoreTestFile(tempDIR, fmt.Sprintf("pkg%02d/file%04d.go", idx%20, idx), fmt.Sprintf("package pkg\n\nconst N%d = %d\n", idx, idx))
+	}
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+
+	_, tree, err := gogitassist.NewRepoTreeWithIgnore(tempDIR)
+	must.Done(err)
+	return tempDIR, repo, tree
+}
+
+// TestFastStatus verifies fast status matches worktree Status across kinds of changes
+// Covers same-size edits, touched files, mode changes, staged, deleted, untracked and ignored files
+//
+// TestFastStatus 验证快速状态在各种变更下与工作树 Status 一致
+// 覆盖同大小编辑、仅触碰的文件、模式变更、已暂存、已删除、未跟踪和被忽略的文件
+func TestFastStatus(t *testing.T) {
+	root, repo, tree := setupFastStatusRepo(t, 40)
+
+	writeIgnoreTestFile(root, "pkg00/file0000.go", "package pkg\n\nconst N0 = 9\n")
+	writeIgnoreTestFile(root, "pkg01/file0001.go", "package pkg\n\nconst N1 = 1\n")
+	must.Done(os.Chtimes(filepath.Join(root, "pkg02/file0002.go"), time.Now(), time.Now().Add(time.Hour)))
+	must.Done(os.Chmod(filepath.Join(root, "pkg03/file0003.go"), 0755))
+	must.Done(os.Remove(filepath.Join(root, "pkg04/file0004.go")))
+	writeIgnoreTestFile(root, "pkg05/file0005.go", "package pkg\n")
+	rese.V1(tree.Add("pkg05/file0005.go"))
+	writeIgnoreTestFile(root, "pkg06/new.go", "package pkg\n")
+	writeIgnoreTestFile(root, "pkg07/debug.log", "log\n")
+	writeIgnoreTestFile(root, "build/out.bin", "binary\n")
+	writeIgnoreTestFile(root, "docs/guide.md", "# Guide\n")
+
+	expected := rese.V1(tree.Status())
+	t.Log(neatjsons.S(expected))
+
+	for _, options := range []*gogitassist.FastStatusOptions{
+		gogitassist.NewFastStatusOptions(),
+		gogitassist.NewFastStatusOptions().Workers(1),
+		gogitassist.NewFastStatusOptions().TrustStat(false),
+	} {
+		status, err := gogitassist.FastStatus(repo, tree, options)
+		require.NoError(t, err)
+		require.Equal(t, expected, status)
+	}
+}
+
+// TestFastStatus_Submodule verifies a submodule is compared by its checked out commit
+// Should match worktree Status before and after the submodule moves to a new commit
+//
+// TestFastStatus_Submodule 验证子模块按其检出的提交进行比较
+// 子模块移动到新提交前后都应该与工作树 Status 一致
+func TestFastStatus_Submodule(t *testing.T) {
+	root, repo, tree := setupFastStatusRepo(t, 4)
+
+	sourceDIR := rese.V1(os.MkdirTemp("", "gogit-fast-status-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(sourceDIR))
+	})
+	writeIgnoreTestFile(sourceDIR, "lib.go", "package lib\n")
+	sourceRepo := rese.P1(gogitassist.InitRepo(sourceDIR))
+	sourceHash := rese.V1(gogitassist.Commit(sourceRepo, "Lib commit", "Test Account", "test@example.com"))
+
+	// Record the submodule in the index and .gitmodules, then let go-git clone and check it out
+	// 在索引和 .gitmodules 中记录子模块，然后由 go-git 克隆并检出
+	writeIgnoreTestFile(root, ".gitmodules", "[submodule \"lib\"]\n\tpath = lib\n\turl = "+filepath.ToSlash(sourceDIR)+"\n")
+	rese.V1(tree.Add(".gitmodules"))
+	idx := rese.P1(repo.Storer.Index())
+	entry := idx.Add("lib")
+	entry.Mode = filemode.Submodule
+	entry.Hash = sourceHash
+	must.Done(repo.Storer.SetIndex(idx))
+	rese.V1(tree.Commit("Add submodule", &git.CommitOptions{Author: &object.Signature{Name: "Test Account", Email: "test@example.com", When: time.Now()}}))
+	submodule := rese.P1(tree.Submodule("lib"))
+	must.Done(submodule.Update(&git.SubmoduleUpdateOptions{Init: true}))
+
+	status, err := gogitassist.FastStatus(repo, tree, gogitassist.NewFastStatusOptions())
+	require.NoError(t, err)
+	require.Equal(t, rese.V1(tree.Status()), status)
+	require.True(t, status.IsClean())
+
+	// A new commit checked out in the submodule shows it as modified
+	// 子模块中检出新提交后显示为已修改
+	submoduleRepo := rese.P1(submodule.Repository())
+	writeIgnoreTestFile(root, "lib/more.go", "package lib\n\nconst More = 1\n")
+	rese.V1(gogitassist.Commit(submoduleRepo, "More lib", "Test Account", "test@example.com"))
+
+	expected := rese.V1(tree.Status())
+	t.Log(neatjsons.S(expected))
+	status, err = gogitassist.FastStatus(repo, tree, gogitassist.NewFastStatusOptions())
+	require.NoError(t, err)
+	require.Equal(t, expected, status)
+	require.Equal(t, git.Modified, status.File("lib").Worktree)
+}
+
+// BenchmarkStatus_Worktree measures go-git worktree Status on a repo of 2000 files
+//
+// BenchmarkStatus_Worktree 测量 go-git 工作树 Status 在 2000 个文件的仓库上的性能
+func BenchmarkStatus_Worktree(b *testing.B) {
+	root, _, tree := setupFastStatusRepo(b, 2000)
+	writeIgnoreTestFile(root, "pkg00/file0000.go", "package pkg\n\nconst N0 = 9\n")
+
+	b.ResetTimer()
+	for range b.N {
+		rese.V1(tree.Status())
+	}
+}
+
+// BenchmarkStatus_Fast measures FastStatus on the same repo of 2000 files
+//
+// BenchmarkStatus_Fast 测量 FastStatus 在相同的 2000 个文件仓库上的性能
+func BenchmarkStatus_Fast(b *testing.B) {
+	root, repo, tree := setupFastStatusRepo(b, 2000)
+	writeIgnoreTestFile(root, "pkg00/file0000.go", "package pkg\n\nconst N0 = 9\n")
+	options := gogitassist.NewFastStatusOptions()
+
+	b.ResetTimer()
+	for range b.N {
+		rese.V1(gogitassist.FastStatus(repo, tree, options))
+	}
+}
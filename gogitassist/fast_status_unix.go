@@ -0,0 +1,18 @@
+//go:build unix
+
+package gogitassist
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode returns the inode number kept in the index, zero when unknown
+//
+// fileInode 返回索引中保存的 inode 编号，未知时为零
+func fileInode(info os.FileInfo) uint32 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint32(stat.Ino)
+	}
+	return 0
+}
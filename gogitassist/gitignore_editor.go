@@ -0,0 +1,281 @@
+package gogitassist
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/yyle88/erero"
+)
+
+// GitignoreEditor edits the root .gitignore of a project while keeping comments and ordering
+// Rules live under named sections, a section is a "# name" comment followed by its rules
+// Edits stay in memory until Save writes them
+// Supports fluent configuration pattern for convenient setup
+//
+// GitignoreEditor 编辑项目根目录的 .gitignore，同时保留注释和顺序
+// 规则位于命名分区下，分区是一行 "# name" 注释及其后的规则
+// 编辑保存在内存中，直到 Save 写入
+// 支持流畅配置模式以便于设置
+type GitignoreEditor struct {
+	root       string   // Project root path // 项目根路径
+	lines      []string // File lines without line endings // 不含换行符的文件行
+	lineEnding string   // Line ending found in the file // 文件中使用的换行符
+	untrack    bool     // Untrack files that become ignored on save // 保存时取消跟踪变为忽略的文件
+}
+
+// EditGitignore loads the root .gitignore of the project into an editor
+// A missing file gives a blank editor, Save creates the file
+//
+// EditGitignore 将项目根目录的 .gitignore 加载到编辑器中
+// 文件不存在时得到空编辑器，Save 会创建该文件
+func EditGitignore(root string) (*GitignoreEditor, error) {
+	editor := &GitignoreEditor{root: root, lineEnding: "\n"}
+	data, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return editor, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	text := string(data)
+	if strings.Contains(text, "\r\n") {
+		editor.lineEnding = "\r\n"
+	}
+	if text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"); text != "" {
+		editor.lines = strings.Split(text, "\n")
+	}
+	return editor, nil
+}
+
+// UntrackIgnored sets whether Save untracks files that become ignored and returns the editor
+// Works like git rm --cached, files stay on disk and just leave the index
+//
+// UntrackIgnored 设置 Save 是否取消跟踪变为忽略的文件并返回编辑器
+// 与 git rm --cached 类似，文件保留在磁盘上，仅从索引中移除
+func (e *GitignoreEditor) UntrackIgnored(enable bool) *GitignoreEditor {
+	e.untrack = enable
+	return e
+}
+
+// Add appends rules under the named section and returns the editor
+// Creates the section at the end when missing, blank section name means the end of file
+// Rules already present anywhere in the file are skipped
+//
+// Add 在命名分区下追加规则并返回编辑器
+// 分区不存在时在末尾创建，分区名为空表示文件末尾
+// 文件中已存在的规则会被跳过
+func (e *GitignoreEditor) Add(section string, rules ...string) *GitignoreEditor {
+	var missing []string
+	for _, rule := range rules {
+		rule = normalizeIgnoreLine(rule)
+		if rule == "" || strings.HasPrefix(rule, "#") || e.hasRule(rule) || slices.Contains(missing, rule) {
+			continue
+		}
+		missing = append(missing, rule)
+	}
+	if len(missing) == 0 {
+		return e
+	}
+
+	position := e.sectionEnd(section)
+	if position < 0 {
+		if len(e.lines) > 0 && strings.TrimSpace(e.lines[len(e.lines)-1]) != "" {
+			e.lines = append(e.lines, "")
+		}
+		if section != "" {
+			e.lines = append(e.lines, "# "+section)
+		}
+		position = len(e.lines)
+	}
+	e.lines = append(e.lines[:position], append(missing, e.lines[position:]...)...)
+	return e
+}
+
+// Remove deletes every line holding one of the rules and returns the editor
+// Comments and blank lines stay untouched
+//
+// Remove 删除包含任一规则的所有行并返回编辑器
+// 注释和空行保持不变
+func (e *GitignoreEditor) Remove(rules ...string) *GitignoreEditor {
+	var targets = make([]string, 0, len(rules))
+	for _, rule := range rules {
+		targets = append(targets, normalizeIgnoreLine(rule))
+	}
+	e.lines = e.filterLines(func(idx int, rule string) bool {
+		return !slices.Contains(targets, rule)
+	})
+	return e
+}
+
+// Dedupe drops repeated rules and returns the editor
+// Keeps the last copy of each rule, since the last matching rule decides, so meaning stays the same
+//
+// Dedupe 删除重复的规则并返回编辑器
+// 保留每条规则的最后一份副本，由于最后匹配的规则生效，因此含义保持不变
+func (e *GitignoreEditor) Dedupe() *GitignoreEditor {
+	var lastIndexes = map[string]int{}
+	for idx, line := range e.lines {
+		if rule := normalizeIgnoreLine(line); isRuleLine(rule) {
+			lastIndexes[rule] = idx
+		}
+	}
+	e.lines = e.filterLines(func(idx int, rule string) bool {
+		return lastIndexes[rule] == idx
+	})
+	return e
+}
+
+// Rules returns the rules in file order, without comments and blank lines
+//
+// Rules 按文件顺序返回规则，不含注释和空行
+func (e *GitignoreEditor) Rules() []string {
+	var rules []string
+	for _, line := range e.lines {
+		if rule := normalizeIgnoreLine(line); isRuleLine(rule) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// String renders the file content, keeping the line ending style of the loaded file
+//
+// String 渲染文件内容，保持所加载文件的换行风格
+func (e *GitignoreEditor) String() string {
+	if len(e.lines) == 0 {
+		return ""
+	}
+	return strings.Join(e.lines, e.lineEnding) + e.lineEnding
+}
+
+// Save writes the .gitignore and untracks files that become ignored when enabled
+// Returns the untracked paths, blank when untracking is disabled
+//
+// Save 写入 .gitignore，并在启用时取消跟踪变为忽略的文件
+// 返回被取消跟踪的路径，未启用取消跟踪时为空
+func (e *GitignoreEditor) Save() ([]string, error) {
+	var previousRules []*IgnoreRule
+	if e.untrack {
+		rules, err := LoadProjectIgnoreRules(e.root)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		previousRules = rules
+	}
+
+	path := filepath.Join(e.root, ".gitignore")
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(path, []byte(e.String()), mode); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	if !e.untrack {
+		return nil, nil
+	}
+	untracked, err := e.untrackIgnored(previousRules)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return untracked, nil
+}
+
+// untrackIgnored removes index entries ignored now but not ignored by previous rules
+//
+// untrackIgnored 移除现在被忽略但之前规则未忽略的索引条目
+func (e *GitignoreEditor) untrackIgnored(previousRules []*IgnoreRule) ([]string, error) {
+	rules, err := LoadProjectIgnoreRules(e.root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	repo, err := NewRepo(e.root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var untracked []string
+	for _, entry := range slices.Clone(idx.Entries) {
+		components := strings.Split(entry.Name, "/")
+		if !matchIgnoredPath(rules, components, false).IsIgnored() || matchIgnoredPath(previousRules, components, false).IsIgnored() {
+			continue
+		}
+		if _, err := idx.Remove(entry.Name); err != nil {
+			return nil, erero.Wro(err)
+		}
+		untracked = append(untracked, entry.Name)
+	}
+	if len(untracked) > 0 {
+		if err := repo.Storer.SetIndex(idx); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	return untracked, nil
+}
+
+// hasRule checks if the rule is present anywhere in the file
+//
+// hasRule 检查规则是否存在于文件中的任何位置
+func (e *GitignoreEditor) hasRule(rule string) bool {
+	return slices.Contains(e.Rules(), rule)
+}
+
+// sectionEnd returns the line index after the rules of the named section, -1 when missing
+// Blank section name refers to the end of a file not ending with a blank line
+//
+// sectionEnd 返回命名分区规则之后的行索引，分区不存在时返回 -1
+// 空分区名指向不以空行结尾的文件末尾
+func (e *GitignoreEditor) sectionEnd(section string) int {
+	if section == "" {
+		if len(e.lines) > 0 && strings.TrimSpace(e.lines[len(e.lines)-1]) != "" {
+			return len(e.lines)
+		}
+		return -1
+	}
+	for idx, line := range e.lines {
+		if !strings.HasPrefix(line, "#") || strings.TrimSpace(strings.TrimPrefix(line, "#")) != section {
+			continue
+		}
+		end := idx + 1
+		for end < len(e.lines) && isRuleLine(normalizeIgnoreLine(e.lines[end])) {
+			end++
+		}
+		return end
+	}
+	return -1
+}
+
+// filterLines keeps comments and blank lines plus the rule lines accepted by keep
+//
+// filterLines 保留注释和空行，以及被 keep 接受的规则行
+func (e *GitignoreEditor) filterLines(keep func(idx int, rule string) bool) []string {
+	var lines = make([]string, 0, len(e.lines))
+	for idx, line := range e.lines {
+		if rule := normalizeIgnoreLine(line); isRuleLine(rule) && !keep(idx, rule) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// normalizeIgnoreLine drops the carriage return and unescaped trailing spaces
+//
+// normalizeIgnoreLine 删除回车符和未转义的尾随空格
+func normalizeIgnoreLine(line string) string {
+	return trimTrailingSpaces(strings.TrimSuffix(line, "\r"))
+}
+
+// isRuleLine checks if a normalized line holds a rule, not a comment or blank
+//
+// isRuleLine 检查规范化后的行是否为规则，而非注释或空行
+func isRuleLine(line string) bool {
+	return line != "" && !strings.HasPrefix(line, "#")
+}
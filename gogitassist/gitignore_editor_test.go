@@ -0,0 +1,67 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestEditGitignore verifies adding under sections, removing and deduping rules
+// Should keep comments, ordering and the CRLF line ending of the file
+//
+// TestEditGitignore 验证在分区下添加、删除以及去重规则
+// 应该保留注释、顺序以及文件的 CRLF 换行符
+func TestEditGitignore(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-edit-ignore-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, ".gitignore", "# Build\r\n/bin\r\n*.log\r\n\r\n# Editor\r\n.idea/\r\n*.log\r\n")
+
+	editor, err := gogitassist.EditGitignore(tempDIR)
+	require.NoError(t, err)
+	editor.Add("Build", "/dist", "/bin").
+		Add("Secrets", ".env").
+		Remove(".idea/").
+		Dedupe()
+	require.Equal(t, []string{"/bin", "/dist", "*.log", ".env"}, editor.Rules())
+
+	untracked, err := editor.Save()
+	require.NoError(t, err)
+	require.Empty(t, untracked)
+	require.Equal(t, "# Build\r\n/bin\r\n/dist\r\n\r\n# Editor\r\n*.log\r\n\r\n# Secrets\r\n.env\r\n", string(rese.V1(os.ReadFile(filepath.Join(tempDIR, ".gitignore")))))
+}
+
+// TestEditGitignore_UntrackIgnored verifies files becoming ignored leave the index but stay on disk
+//
+// TestEditGitignore_UntrackIgnored 验证变为忽略的文件离开索引但保留在磁盘上
+func TestEditGitignore_UntrackIgnored(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-edit-ignore-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	writeIgnoreTestFile(tempDIR, "main.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "build/out.bin", "binary\n")
+	writeIgnoreTestFile(tempDIR, "debug.log", "log\n")
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+
+	editor, err := gogitassist.EditGitignore(tempDIR)
+	require.NoError(t, err)
+	untracked, err := editor.Add("Generated", "build/", "*.log").UntrackIgnored(true).Save()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"build/out.bin", "debug.log"}, untracked)
+
+	idx := rese.P1(repo.Storer.Index())
+	var names []string
+	for _, entry := range idx.Entries {
+		names = append(names, entry.Name)
+	}
+	require.Equal(t, []string{"main.go"}, names)
+	require.FileExists(t, filepath.Join(tempDIR, "debug.log"))
+}
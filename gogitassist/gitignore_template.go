@@ -0,0 +1,111 @@
+package gogitassist
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/yyle88/erero"
+)
+
+// gitignoreTemplateFiles holds the built-in templates, one "<name>.gitignore" file each
+// The first line of each file is a "# Title" comment naming its section
+//
+// gitignoreTemplateFiles 保存内置模板，每个模板对应一个 "<name>.gitignore" 文件
+// 每个文件的第一行是命名其分区的 "# Title" 注释
+//
+//go:embed gitignore_templates/*.gitignore
+var gitignoreTemplateFiles embed.FS
+
+// GitignoreTemplateNames returns the names of built-in templates in sorted order
+// Names include "go", "node", "python", "ide" and "os"
+//
+// GitignoreTemplateNames 按排序顺序返回内置模板的名称
+// 名称包括 "go"、"node"、"python"、"ide" 和 "os"
+func GitignoreTemplateNames() []string {
+	entries, _ := gitignoreTemplateFiles.ReadDir("gitignore_templates")
+	var names = make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".gitignore"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ComposeGitignoreTemplates composes the named templates into one .gitignore text
+// Each template becomes a section, rules repeated across templates appear once
+// Returns error when a name is unknown
+//
+// ComposeGitignoreTemplates 将指定的模板组合为一份 .gitignore 文本
+// 每个模板成为一个分区，跨模板重复的规则只出现一次
+// 名称未知时返回错误
+func ComposeGitignoreTemplates(names ...string) (string, error) {
+	editor := &GitignoreEditor{lineEnding: "\n"}
+	if err := addGitignoreTemplates(editor, names); err != nil {
+		return "", erero.Wro(err)
+	}
+	return editor.String(), nil
+}
+
+// MergeGitignoreTemplates merges the named templates into the root .gitignore of the project
+// Existing content and comments stay, rules already present are not added again
+// Creates the .gitignore when missing
+//
+// MergeGitignoreTemplates 将指定的模板合并到项目根目录的 .gitignore 中
+// 保留已有内容和注释，已存在的规则不会重复添加
+// .gitignore 不存在时会创建该文件
+func MergeGitignoreTemplates(root string, names ...string) error {
+	editor, err := EditGitignore(root)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if err := addGitignoreTemplates(editor, names); err != nil {
+		return erero.Wro(err)
+	}
+	if _, err := editor.Save(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// InitRepoWithGitignore initializes a new Git repo and writes a .gitignore from the named templates
+// Shortcut of InitRepoWithOptions with just GitignoreTemplates set
+//
+// InitRepoWithGitignore 初始化新的 Git 仓库，并根据指定的模板写入 .gitignore
+// 相当于仅设置 GitignoreTemplates 的 InitRepoWithOptions
+func InitRepoWithGitignore(root string, names ...string) (*git.Repository, error) {
+	repo, err := InitRepoWithOptions(root, NewInitOptions().GitignoreTemplates(names...))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return repo, nil
+}
+
+// addGitignoreTemplates adds the rules of each named template under its section
+//
+// addGitignoreTemplates 将每个指定模板的规则添加到其分区下
+func addGitignoreTemplates(editor *GitignoreEditor, names []string) error {
+	for _, name := range names {
+		section, rules, err := loadGitignoreTemplate(name)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		editor.Add(section, rules...)
+	}
+	return nil
+}
+
+// loadGitignoreTemplate reads a built-in template, returning its section title and rules
+//
+// loadGitignoreTemplate 读取内置模板，返回其分区标题和规则
+func loadGitignoreTemplate(name string) (string, []string, error) {
+	data, err := gitignoreTemplateFiles.ReadFile(path.Join("gitignore_templates", strings.ToLower(name)+".gitignore"))
+	if err != nil {
+		return "", nil, erero.Errorf("unknown gitignore template %q, available: %s", name, strings.Join(GitignoreTemplateNames(), ", "))
+	}
+	editor := &GitignoreEditor{lineEnding: "\n", lines: strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")}
+	section := strings.TrimSpace(strings.TrimPrefix(editor.lines[0], "#"))
+	return section, editor.Rules(), nil
+}
@@ -0,0 +1,53 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestComposeGitignoreTemplates verifies composing templates into sections without repeated rules
+//
+// TestComposeGitignoreTemplates 验证将模板组合为分区且规则不重复
+func TestComposeGitignoreTemplates(t *testing.T) {
+	require.Equal(t, []string{"go", "ide", "node", "os", "python"}, gogitassist.GitignoreTemplateNames())
+
+	text, err := gogitassist.ComposeGitignoreTemplates("node", "Python", "os")
+	require.NoError(t, err)
+	t.Log(text)
+	require.True(t, strings.HasPrefix(text, "# Node\nnode_modules/\n"))
+	require.Contains(t, text, "\n\n# Python\n__pycache__/\n")
+	require.Contains(t, text, "\n\n# OS\n.DS_Store\n")
+	require.Equal(t, 1, strings.Count(text, "dist/\n"))
+
+	_, err = gogitassist.ComposeGitignoreTemplates("go", "cobol")
+	require.ErrorContains(t, err, "cobol")
+}
+
+// TestInitRepoWithGitignore verifies writing templates during init and merging into existing files
+//
+// TestInitRepoWithGitignore 验证在初始化时写入模板以及合并到已有文件
+func TestInitRepoWithGitignore(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-template-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, ".gitignore", "# Local\n*.exe\n")
+
+	_, err := gogitassist.InitRepoWithGitignore(tempDIR, "go", "ide")
+	require.NoError(t, err)
+	require.NoError(t, gogitassist.MergeGitignoreTemplates(tempDIR, "go"))
+
+	text := string(rese.V1(os.ReadFile(filepath.Join(tempDIR, ".gitignore"))))
+	t.Log(text)
+	require.True(t, strings.HasPrefix(text, "# Local\n*.exe\n\n# Go\n*.exe~\n"))
+	require.Equal(t, 1, strings.Count(text, "*.exe\n"))
+	require.Equal(t, 1, strings.Count(text, "# Go\n"))
+	require.Contains(t, text, "# IDE\n.idea/\n")
+}
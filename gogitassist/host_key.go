@@ -0,0 +1,343 @@
+package gogitassist
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexistpath/osomitexist"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// HostKeyErrorKind tells why a host key was rejected
+//
+// HostKeyErrorKind 说明主机密钥被拒绝的原因
+type HostKeyErrorKind string
+
+const (
+	HostKeyUnknown  HostKeyErrorKind = "unknown"  // Host not in known_hosts and not pinned // 主机不在 known_hosts 中且未固定
+	HostKeyMismatch HostKeyErrorKind = "mismatch" // Host key differs from known_hosts or the pins // 主机密钥与 known_hosts 或固定值不同
+	HostKeyRevoked  HostKeyErrorKind = "revoked"  // Host key marked @revoked in known_hosts // 主机密钥在 known_hosts 中被标记为 @revoked
+)
+
+// HostKeyError reports a failed SSH host key verification
+// Network operations return it wrapped, so match it with errors.As
+//
+// HostKeyError 报告 SSH 主机密钥校验失败
+// 网络操作返回的是包装后的错误，因此请使用 errors.As 匹配
+type HostKeyError struct {
+	Kind        HostKeyErrorKind // Why the key was rejected // 密钥被拒绝的原因
+	Host        string           // Host as host:port // 以 host:port 表示的主机
+	Fingerprint string           // SHA256 fingerprint of the key presented // 服务器出示密钥的 SHA256 指纹
+	Expected    []string         // SHA256 fingerprints expected, blank when unknown // 期望的 SHA256 指纹，未知主机时为空
+}
+
+// Error describes the rejected key
+//
+// Error 描述被拒绝的密钥
+func (e *HostKeyError) Error() string {
+	if len(e.Expected) > 0 {
+		return fmt.Sprintf("ssh host key %s: host %s presented %s, expected %s", e.Kind, e.Host, e.Fingerprint, strings.Join(e.Expected, ", "))
+	}
+	return fmt.Sprintf("ssh host key %s: host %s presented %s", e.Kind, e.Host, e.Fingerprint)
+}
+
+// HostKeyPolicy configures how SSH host keys get verified
+// Supports fluent configuration pattern for convenient setup
+//
+// HostKeyPolicy 配置 SSH 主机密钥的校验方式
+// 支持流畅配置模式以便于设置
+type HostKeyPolicy struct {
+	knownHostsPaths []string            // known_hosts files, the first one takes new keys // known_hosts 文件，第一个文件接收新密钥
+	pins            map[string][]string // SHA256 fingerprints by host or host:port // 按 host 或 host:port 索引的 SHA256 指纹
+	acceptNew       bool                // Record keys of unknown hosts, like StrictHostKeyChecking=accept-new // 记录未知主机的密钥，类似 StrictHostKeyChecking=accept-new
+	insecure        bool                // Accept any key, just use it in tests // 接受任何密钥，仅用于测试
+}
+
+// NewHostKeyPolicy creates a strict policy reading the default known_hosts files
+// Those are $SSH_KNOWN_HOSTS, else ~/.ssh/known_hosts and /etc/ssh/ssh_known_hosts, as go-git uses
+//
+// NewHostKeyPolicy 创建读取默认 known_hosts 文件的严格策略
+// 与 go-git 相同，默认文件为 $SSH_KNOWN_HOSTS，否则为 ~/.ssh/known_hosts 和 /etc/ssh/ssh_known_hosts
+func NewHostKeyPolicy() *HostKeyPolicy {
+	return &HostKeyPolicy{
+		pins: map[string][]string{},
+	}
+}
+
+// KnownHostsPaths sets the known_hosts files and returns updated HostKeyPolicy
+// Missing files are skipped, the first path takes new keys with AcceptNew
+//
+// KnownHostsPaths 设置 known_hosts 文件并返回更新的 HostKeyPolicy
+// 不存在的文件会被跳过，启用 AcceptNew 时第一个路径接收新密钥
+func (p *HostKeyPolicy) KnownHostsPaths(paths ...string) *HostKeyPolicy {
+	p.knownHostsPaths = paths
+	return p
+}
+
+// PinFingerprint pins the SHA256 fingerprints accepted from a host and returns updated HostKeyPolicy
+// Host is a name, or host:port to pin a single port, fingerprints look like ssh-keygen -l prints,
+// such as SHA256:+DiY3wvvV6TuJJhbpZisF/zLDA0zPMSvHdkr4UvCOqU
+// Pins take the place of known_hosts with that host, so pin each key type the server may offer
+//
+// PinFingerprint 固定从主机接受的 SHA256 指纹并返回更新的 HostKeyPolicy
+// host 为主机名，或使用 host:port 仅固定单个端口，指纹格式与 ssh-keygen -l 的输出相同，
+// 如 SHA256:+DiY3wvvV6TuJJhbpZisF/zLDA0zPMSvHdkr4UvCOqU
+// 对该主机固定值会取代 known_hosts，因此需要固定服务器可能提供的每种密钥类型
+func (p *HostKeyPolicy) PinFingerprint(host string, fingerprints ...string) *HostKeyPolicy {
+	host = strings.ToLower(host)
+	p.pins[host] = append(p.pins[host], fingerprints...)
+	return p
+}
+
+// AcceptNew sets whether keys of unknown hosts get recorded and accepted and returns updated HostKeyPolicy
+// Changed keys of known hosts are still rejected, like StrictHostKeyChecking=accept-new
+//
+// AcceptNew 设置是否记录并接受未知主机的密钥并返回更新的 HostKeyPolicy
+// 与 StrictHostKeyChecking=accept-new 一样，已知主机的密钥变化仍会被拒绝
+func (p *HostKeyPolicy) AcceptNew(acceptNew bool) *HostKeyPolicy {
+	p.acceptNew = acceptNew
+	return p
+}
+
+// Insecure sets whether any host key is accepted and returns updated HostKeyPolicy
+// Turns off verification, so use it in tests against local servers, not in production
+//
+// Insecure 设置是否接受任何主机密钥并返回更新的 HostKeyPolicy
+// 会关闭校验，因此仅用于针对本地服务器的测试，不要用于生产环境
+func (p *HostKeyPolicy) Insecure(insecure bool) *HostKeyPolicy {
+	p.insecure = insecure
+	return p
+}
+
+// HostKeyCallback builds the ssh callback verifying host keys with this policy
+// Rejections are returned as *HostKeyError
+//
+// HostKeyCallback 构建按此策略校验主机密钥的 ssh 回调
+// 拒绝时返回 *HostKeyError
+func (p *HostKeyPolicy) HostKeyCallback() (ssh.HostKeyCallback, error) {
+	if p.insecure {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+	paths, err := p.resolveKnownHostsPaths()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	checker := &hostKeyChecker{policy: p, paths: paths}
+	if err := checker.load(); err != nil {
+		return nil, erero.Wro(err)
+	}
+	return checker.check, nil
+}
+
+// Apply sets this policy on a go-git SSH auth method, with the key algorithms known of the URL host
+// Other auth methods are left as they are
+//
+// Apply 在 go-git SSH 认证方式上设置此策略，并使用 URL 主机已知的密钥算法
+// 其他认证方式保持不变
+func (p *HostKeyPolicy) Apply(auth transport.AuthMethod, remoteURL *RemoteURL) error {
+	var helper *gitssh.HostKeyCallbackHelper
+	switch method := auth.(type) {
+	case *gitssh.PublicKeys:
+		helper = &method.HostKeyCallbackHelper
+	case *gitssh.PublicKeysCallback:
+		helper = &method.HostKeyCallbackHelper
+	case *gitssh.Password:
+		helper = &method.HostKeyCallbackHelper
+	case *gitssh.PasswordCallback:
+		helper = &method.HostKeyCallbackHelper
+	case *gitssh.KeyboardInteractive:
+		helper = &method.HostKeyCallbackHelper
+	default:
+		return nil
+	}
+	callback, err := p.HostKeyCallback()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	helper.HostKeyCallback = callback
+	helper.HostKeyAlgorithms = nil
+	if p.insecure || len(p.lookupPins(remoteURL.Host, sshPort(remoteURL))) > 0 {
+		return nil
+	}
+	// Offer the key types known of the host first, so the server does not present another type
+	// 优先提供主机已知的密钥类型，避免服务器出示其他类型的密钥
+	paths, err := p.resolveKnownHostsPaths()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if existing := filterExistingPaths(paths); len(existing) > 0 {
+		db, err := gitssh.NewKnownHostsDb(existing...)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		helper.HostKeyAlgorithms = db.HostKeyAlgorithms(net.JoinHostPort(remoteURL.Host, strconv.Itoa(sshPort(remoteURL))))
+	}
+	return nil
+}
+
+// resolveKnownHostsPaths returns the set paths, else the default ones go-git reads
+//
+// resolveKnownHostsPaths 返回设置的路径，否则返回 go-git 读取的默认路径
+func (p *HostKeyPolicy) resolveKnownHostsPaths() ([]string, error) {
+	if len(p.knownHostsPaths) > 0 {
+		return p.knownHostsPaths, nil
+	}
+	if paths := filepath.SplitList(os.Getenv("SSH_KNOWN_HOSTS")); len(paths) > 0 {
+		return paths, nil
+	}
+	homeDIR, err := os.UserHomeDir()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return []string{filepath.Join(homeDIR, ".ssh", "known_hosts"), "/etc/ssh/ssh_known_hosts"}, nil
+}
+
+// lookupPins returns the fingerprints pinned to host:port, else to host
+//
+// lookupPins 返回固定到 host:port 的指纹，否则返回固定到 host 的指纹
+func (p *HostKeyPolicy) lookupPins(host string, port int) []string {
+	host = strings.ToLower(host)
+	if pins, ok := p.pins[net.JoinHostPort(host, strconv.Itoa(port))]; ok {
+		return pins
+	}
+	return p.pins[host]
+}
+
+// sshPort returns the port of the URL, 22 when not given
+//
+// sshPort 返回 URL 的端口，未给出时为 22
+func sshPort(remoteURL *RemoteURL) int {
+	if remoteURL.Port != 0 {
+		return remoteURL.Port
+	}
+	return 22
+}
+
+// filterExistingPaths keeps the paths of existing files
+//
+// filterExistingPaths 保留存在的文件路径
+func filterExistingPaths(paths []string) []string {
+	var existing []string
+	for _, path := range paths {
+		if osomitexist.IsFile(path) {
+			existing = append(existing, path)
+		}
+	}
+	return existing
+}
+
+// hostKeyChecker verifies host keys against pins and known_hosts, recording new keys with accept-new
+//
+// hostKeyChecker 根据固定值和 known_hosts 校验主机密钥，启用 accept-new 时记录新密钥
+type hostKeyChecker struct {
+	policy   *HostKeyPolicy      // Policy in use // 使用的策略
+	paths    []string            // known_hosts files // known_hosts 文件
+	mutex    sync.Mutex          // Guards callback and the first known_hosts file // 保护回调和第一个 known_hosts 文件
+	callback ssh.HostKeyCallback // known_hosts callback, nil without files // known_hosts 回调，没有文件时为 nil
+}
+
+// load reads the existing known_hosts files into the callback
+//
+// load 将存在的 known_hosts 文件读入回调
+func (c *hostKeyChecker) load() error {
+	existing := filterExistingPaths(c.paths)
+	if len(existing) == 0 {
+		c.callback = nil
+		return nil
+	}
+	callback, err := knownhosts.New(existing...)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	c.callback = callback
+	return nil
+}
+
+// check is the ssh host key callback
+//
+// check 是 ssh 主机密钥回调
+func (c *hostKeyChecker) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	fingerprint := ssh.FingerprintSHA256(key)
+	host, portText, err := net.SplitHostPort(hostname)
+	if err != nil {
+		host, portText = hostname, "22"
+	}
+	port, _ := strconv.Atoi(portText)
+	if pins := c.policy.lookupPins(host, port); len(pins) > 0 {
+		if slices.Contains(pins, fingerprint) {
+			return nil
+		}
+		return &HostKeyError{Kind: HostKeyMismatch, Host: hostname, Fingerprint: fingerprint, Expected: pins}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.callback != nil {
+		err := c.callback(hostname, remote, key)
+		if err == nil {
+			return nil
+		}
+		var revokedError *knownhosts.RevokedError
+		if errors.As(err, &revokedError) {
+			return &HostKeyError{Kind: HostKeyRevoked, Host: hostname, Fingerprint: fingerprint}
+		}
+		var keyError *knownhosts.KeyError
+		if !errors.As(err, &keyError) {
+			return erero.Wro(err)
+		}
+		if len(keyError.Want) > 0 {
+			var expected []string
+			for _, want := range keyError.Want {
+				expected = append(expected, ssh.FingerprintSHA256(want.Key))
+			}
+			return &HostKeyError{Kind: HostKeyMismatch, Host: hostname, Fingerprint: fingerprint, Expected: expected}
+		}
+	}
+	if !c.policy.acceptNew {
+		return &HostKeyError{Kind: HostKeyUnknown, Host: hostname, Fingerprint: fingerprint}
+	}
+	if err := c.record(hostname, key); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// record appends the key to the first known_hosts file and reloads the callback
+//
+// record 将密钥追加到第一个 known_hosts 文件并重新加载回调
+func (c *hostKeyChecker) record(hostname string, key ssh.PublicKey) error {
+	if len(c.paths) == 0 {
+		return erero.New("no known_hosts file to record new host keys")
+	}
+	path := c.paths[0]
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return erero.Wro(err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	line := knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key) + "\n"
+	if _, err := file.WriteString(line); err != nil {
+		_ = file.Close()
+		return erero.Wro(err)
+	}
+	if err := file.Close(); err != nil {
+		return erero.Wro(err)
+	}
+	if err := c.load(); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package gogitassist_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// newTestHostKey generates an ed25519 key pair used as SSH host key
+//
+// newTestHostKey 生成用作 SSH 主机密钥的 ed25519 密钥对
+func newTestHostKey() ssh.Signer {
+	_, privateKey := rese.V2(ed25519.GenerateKey(rand.Reader))
+	return rese.V1(ssh.NewSignerFromKey(privateKey))
+}
+
+// TestHostKeyPolicy verifies strict, accept-new, pinned, revoked and insecure verification
+//
+// TestHostKeyPolicy 验证严格、accept-new、固定指纹、吊销以及不安全模式的校验
+func TestHostKeyPolicy(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-host-key-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	knownHostsPath := filepath.Join(tempDIR, "ssh", "known_hosts")
+	remote := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22}
+	hostKey := newTestHostKey().PublicKey()
+	otherKey := newTestHostKey().PublicKey()
+
+	requireHostKeyError := func(err error, kind gogitassist.HostKeyErrorKind) *gogitassist.HostKeyError {
+		var hostKeyError *gogitassist.HostKeyError
+		require.True(t, errors.As(err, &hostKeyError), err)
+		require.Equal(t, kind, hostKeyError.Kind)
+		require.Equal(t, ssh.FingerprintSHA256(otherKey), hostKeyError.Fingerprint)
+		t.Log(hostKeyError.Error())
+		return hostKeyError
+	}
+
+	strict := rese.V1(gogitassist.NewHostKeyPolicy().KnownHostsPaths(knownHostsPath).HostKeyCallback())
+	err := strict("git.example.com:22", remote, hostKey)
+	var hostKeyError *gogitassist.HostKeyError
+	require.True(t, errors.As(err, &hostKeyError))
+	require.Equal(t, gogitassist.HostKeyUnknown, hostKeyError.Kind)
+	require.NoFileExists(t, knownHostsPath)
+
+	acceptNew := rese.V1(gogitassist.NewHostKeyPolicy().KnownHostsPaths(knownHostsPath).AcceptNew(true).HostKeyCallback())
+	require.NoError(t, acceptNew("git.example.com:22", remote, hostKey))
+	require.NoError(t, acceptNew("git.example.com:22", remote, hostKey))
+	require.NoError(t, acceptNew("git.example.com:2222", remote, hostKey))
+	mismatch := requireHostKeyError(acceptNew("git.example.com:22", remote, otherKey), gogitassist.HostKeyMismatch)
+	require.Equal(t, []string{ssh.FingerprintSHA256(hostKey)}, mismatch.Expected)
+
+	strict = rese.V1(gogitassist.NewHostKeyPolicy().KnownHostsPaths(knownHostsPath).HostKeyCallback())
+	require.NoError(t, strict("git.example.com:22", remote, hostKey))
+	require.NoError(t, strict("[git.example.com]:2222", remote, hostKey))
+	requireHostKeyError(strict("git.example.com:22", remote, otherKey), gogitassist.HostKeyMismatch)
+
+	pinned := rese.V1(gogitassist.NewHostKeyPolicy().
+		KnownHostsPaths(knownHostsPath).
+		PinFingerprint("pinned.example.com", ssh.FingerprintSHA256(hostKey)).
+		PinFingerprint("git.example.com:2222", ssh.FingerprintSHA256(hostKey)).
+		HostKeyCallback())
+	require.NoError(t, pinned("pinned.example.com:22", remote, hostKey))
+	requireHostKeyError(pinned("pinned.example.com:22", remote, otherKey), gogitassist.HostKeyMismatch)
+	requireHostKeyError(pinned("git.example.com:2222", remote, otherKey), gogitassist.HostKeyMismatch)
+
+	revokedPath := filepath.Join(tempDIR, "revoked_hosts")
+	must.Done(os.WriteFile(revokedPath, []byte("@revoked * "+string(ssh.MarshalAuthorizedKey(otherKey))), 0600))
+	revoked := rese.V1(gogitassist.NewHostKeyPolicy().KnownHostsPaths(revokedPath).AcceptNew(true).HostKeyCallback())
+	requireHostKeyError(revoked("git.example.com:22", remote, otherKey), gogitassist.HostKeyRevoked)
+
+	insecure := rese.V1(gogitassist.NewHostKeyPolicy().KnownHostsPaths(knownHostsPath).Insecure(true).HostKeyCallback())
+	require.NoError(t, insecure("git.example.com:22", remote, otherKey))
+}
+
+// TestHostKeyPolicy_Apply verifies the policy goes on SSH auth methods with the known key algorithms
+//
+// TestHostKeyPolicy_Apply 验证策略连同已知的密钥算法被设置到 SSH 认证方式上
+func TestHostKeyPolicy_Apply(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-host-key-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	knownHostsPath := filepath.Join(tempDIR, "known_hosts")
+	hostKey := newTestHostKey().PublicKey()
+	must.Done(os.WriteFile(knownHostsPath, []byte(knownhosts.Line([]string{"git.example.com"}, hostKey)+"\n"), 0600))
+	policy := gogitassist.NewHostKeyPolicy().KnownHostsPaths(knownHostsPath)
+
+	auth := &gitssh.PublicKeys{User: "git", Signer: newTestHostKey()}
+	require.NoError(t, policy.Apply(auth, rese.P1(gogitassist.ParseRemoteURL("git@git.example.com:team/repo.git"))))
+	require.NotNil(t, auth.HostKeyCallback)
+	require.Equal(t, []string{ssh.KeyAlgoED25519}, auth.HostKeyAlgorithms)
+
+	resolved, err := gogitassist.ResolveAuthMethod(gogitassist.AuthProviderFunc(func(remoteURL *gogitassist.RemoteURL) (transport.AuthMethod, error) {
+		return &gitssh.PublicKeys{User: "git", Signer: newTestHostKey()}, nil
+	}), policy, rese.P1(gogitassist.ParseRemoteURL("ssh://git@git.example.com/team/repo.git")))
+	require.NoError(t, err)
+	require.NotNil(t, resolved.(*gitssh.PublicKeys).HostKeyCallback)
+}
+
+// TestClone_HostKeyRejected verifies a clone from an SSH server with unknown host key fails with HostKeyError
+//
+// TestClone_HostKeyRejected 验证从主机密钥未知的 SSH 服务器克隆时返回 HostKeyError
+func TestClone_HostKeyRejected(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-host-key-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+
+	// A bare SSH server doing just the handshake, which the client aborts on the host key
+	// 只执行握手的简易 SSH 服务器，客户端会因主机密钥而中止握手
+	serverConfig := &ssh.ServerConfig{NoClientAuth: true}
+	serverConfig.AddHostKey(newTestHostKey())
+	listener := rese.V1(net.Listen("tcp", "127.0.0.1:0"))
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _, _, _ = ssh.NewServerConn(conn, serverConfig)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	options := gogitassist.NewCloneOptions().
+		Auth(gogitassist.AuthProviderFunc(func(remoteURL *gogitassist.RemoteURL) (transport.AuthMethod, error) {
+			return &gitssh.PublicKeys{User: "git", Signer: newTestHostKey()}, nil
+		})).
+		HostKeyPolicy(gogitassist.NewHostKeyPolicy().KnownHostsPaths(filepath.Join(tempDIR, "known_hosts")))
+	remoteURL := "ssh://git@" + listener.Addr().String() + "/team/repo.git"
+	_, _, err := gogitassist.Clone(remoteURL, filepath.Join(tempDIR, "clone"), options)
+	require.Error(t, err)
+
+	var hostKeyError *gogitassist.HostKeyError
+	require.True(t, errors.As(err, &hostKeyError), err)
+	require.Equal(t, gogitassist.HostKeyUnknown, hostKeyError.Kind)
+	require.Equal(t, listener.Addr().String(), hostKeyError.Host)
+}
@@ -0,0 +1,254 @@
+package gogitassist
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	formatconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/yyle88/erero"
+)
+
+// IgnoreOptions chooses which layers provide the core.excludesFile ignore rules
+// The project layers (.git/info/exclude and .gitignore files) always apply
+// Supports fluent configuration pattern for convenient setup
+//
+// IgnoreOptions 选择由哪些层提供 core.excludesFile 忽略规则
+// 项目层（.git/info/exclude 和 .gitignore 文件）始终生效
+// 支持流畅配置模式以便于设置
+type IgnoreOptions struct {
+	systemConfig        bool // Read core.excludesFile from system config // 从系统配置读取 core.excludesFile
+	globalConfig        bool // Read core.excludesFile from user config // 从用户配置读取 core.excludesFile
+	localConfig         bool // Read core.excludesFile from repo config // 从仓库配置读取 core.excludesFile
+	defaultExcludesFile bool // Use XDG git/ignore when core.excludesFile is unset // 未设置 core.excludesFile 时使用 XDG git/ignore
+}
+
+// NewIgnoreOptions creates options enabling every layer, matching the git CLI
+//
+// NewIgnoreOptions 创建启用所有层的选项，与 git 命令行一致
+func NewIgnoreOptions() *IgnoreOptions {
+	return &IgnoreOptions{
+		systemConfig:        true,
+		globalConfig:        true,
+		localConfig:         true,
+		defaultExcludesFile: true,
+	}
+}
+
+// SystemConfig sets whether /etc/gitconfig may set core.excludesFile and returns updated IgnoreOptions
+// Like the git CLI, GIT_CONFIG_SYSTEM names another file and GIT_CONFIG_NOSYSTEM skips it
+//
+// SystemConfig 设置 /etc/gitconfig 是否可以设置 core.excludesFile 并返回更新的 IgnoreOptions
+// 与 git 命令行一致，GIT_CONFIG_SYSTEM 指定其他文件，GIT_CONFIG_NOSYSTEM 跳过该文件
+func (o *IgnoreOptions) SystemConfig(enable bool) *IgnoreOptions {
+	o.systemConfig = enable
+	return o
+}
+
+// GlobalConfig sets whether user config files may set core.excludesFile and returns updated IgnoreOptions
+// Like the git CLI, GIT_CONFIG_GLOBAL names one file in place of both user config files
+//
+// GlobalConfig 设置用户配置文件是否可以设置 core.excludesFile 并返回更新的 IgnoreOptions
+// 与 git 命令行一致，GIT_CONFIG_GLOBAL 指定一个文件取代两个用户配置文件
+func (o *IgnoreOptions) GlobalConfig(enable bool) *IgnoreOptions {
+	o.globalConfig = enable
+	return o
+}
+
+// LocalConfig sets whether .git/config may set core.excludesFile and returns updated IgnoreOptions
+//
+// LocalConfig 设置 .git/config 是否可以设置 core.excludesFile 并返回更新的 IgnoreOptions
+func (o *IgnoreOptions) LocalConfig(enable bool) *IgnoreOptions {
+	o.localConfig = enable
+	return o
+}
+
+// DefaultExcludesFile sets whether $XDG_CONFIG_HOME/git/ignore applies when core.excludesFile is unset
+// Falls back to ~/.config/git/ignore when XDG_CONFIG_HOME is blank
+//
+// DefaultExcludesFile 设置未设置 core.excludesFile 时是否应用 $XDG_CONFIG_HOME/git/ignore
+// XDG_CONFIG_HOME 为空时回退到 ~/.config/git/ignore
+func (o *IgnoreOptions) DefaultExcludesFile(enable bool) *IgnoreOptions {
+	o.defaultExcludesFile = enable
+	return o
+}
+
+// NewRepoTreeWithIgnoreOptions creates repo and worktree with ignore layers chosen by options
+// Load failures such as unreadable files or broken config are returned, not skipped
+//
+// NewRepoTreeWithIgnoreOptions 创建带有由选项选择的忽略层的仓库和工作树
+// 文件不可读或配置损坏等加载失败会被返回，而不是被跳过
+func NewRepoTreeWithIgnoreOptions(root string, options *IgnoreOptions) (*git.Repository, *git.Worktree, error) {
+	repo, err := NewRepo(root)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	tree, err := repo.Worktree()
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	rules, err := LoadIgnoreRules(root, options)
+	if err != nil {
+		return nil, nil, erero.Wro(err)
+	}
+	// go-git checks worktree excludes above its own patterns, so the full ordered list is needed
+	// go-git 将工作树排除规则置于其自身模式之上，因此需要完整的有序列表
+	SetIgnorePatterns(tree, IgnoreRulePatterns(rules))
+	return repo, tree, nil
+}
+
+// LoadIgnoreRules loads ignore rules of every layer chosen by options, in ascending precedence
+// Order follows git: core.excludesFile < .git/info/exclude < .gitignore files
+//
+// LoadIgnoreRules 按优先级升序加载由选项选择的所有层的忽略规则
+// 顺序与 git 一致：core.excludesFile < .git/info/exclude < .gitignore 文件
+func LoadIgnoreRules(root string, options *IgnoreOptions) ([]*IgnoreRule, error) {
+	excludesFile, err := ResolveExcludesFile(root, options)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var rules []*IgnoreRule
+	if excludesFile != "" {
+		if rules, err = LoadIgnoreRulesFromPath(excludesFile, nil); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	projectRules, err := LoadProjectIgnoreRules(root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return append(rules, projectRules...), nil
+}
+
+// ResolveExcludesFile finds the excludes file git would use, blank when none applies
+// Reads core.excludesFile from system, user and repo config in that order, the last one wins
+// Config paths follow GIT_CONFIG_SYSTEM, GIT_CONFIG_GLOBAL and GIT_CONFIG_NOSYSTEM like the git CLI
+// Expands a leading "~/" and resolves relative paths against root
+//
+// ResolveExcludesFile 查找 git 会使用的排除文件，无适用文件时返回空
+// 依次从系统、用户和仓库配置读取 core.excludesFile，最后一个生效
+// 与 git 命令行一致，配置路径遵循 GIT_CONFIG_SYSTEM、GIT_CONFIG_GLOBAL 和 GIT_CONFIG_NOSYSTEM
+// 展开开头的 "~/"，并相对于 root 解析相对路径
+func ResolveExcludesFile(root string, options *IgnoreOptions) (string, error) {
+	var configPaths []string
+	if options.systemConfig && os.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		if systemPath, ok := os.LookupEnv("GIT_CONFIG_SYSTEM"); ok {
+			configPaths = append(configPaths, systemPath)
+		} else {
+			configPaths = append(configPaths, "/etc/gitconfig")
+		}
+	}
+	if options.globalConfig {
+		if globalPath, ok := os.LookupEnv("GIT_CONFIG_GLOBAL"); ok {
+			configPaths = append(configPaths, globalPath)
+		} else {
+			if configHome := xdgConfigHome(); configHome != "" {
+				configPaths = append(configPaths, filepath.Join(configHome, "git", "config"))
+			}
+			if home, err := os.UserHomeDir(); err == nil {
+				configPaths = append(configPaths, filepath.Join(home, ".gitconfig"))
+			}
+		}
+	}
+
+	var excludesFile string
+	for _, configPath := range configPaths {
+		// A blank path from the env names no file
+		// 环境变量给出的空路径表示没有文件
+		if configPath == "" {
+			continue
+		}
+		value, err := readExcludesFileOption(configPath)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		if value != "" {
+			excludesFile = value
+		}
+	}
+	if options.localConfig {
+		value, err := readRepoExcludesFileOption(root)
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		if value != "" {
+			excludesFile = value
+		}
+	}
+	if excludesFile == "" {
+		configHome := xdgConfigHome()
+		if !options.defaultExcludesFile || configHome == "" {
+			return "", nil
+		}
+		return filepath.Join(configHome, "git", "ignore"), nil
+	}
+
+	if rest, ok := strings.CutPrefix(excludesFile, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", erero.Wro(err)
+		}
+		excludesFile = filepath.Join(home, rest)
+	}
+	if !filepath.IsAbs(excludesFile) {
+		excludesFile = filepath.Join(root, excludesFile)
+	}
+	return excludesFile, nil
+}
+
+// readExcludesFileOption reads core.excludesFile from one config file, blank when file is missing
+//
+// readExcludesFileOption 从单个配置文件读取 core.excludesFile，文件不存在时返回空
+func readExcludesFileOption(configPath string) (string, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", erero.Wro(err)
+	}
+	var cfg = formatconfig.New()
+	if err := formatconfig.NewDecoder(bytes.NewReader(data)).Decode(cfg); err != nil {
+		return "", erero.Wrapf(err, "wrong config %s", configPath)
+	}
+	return cfg.Section("core").Option("excludesfile"), nil
+}
+
+// readRepoExcludesFileOption reads core.excludesFile from repo config through the go-git storer
+// The storer finds the config behind a .git file too, as in linked worktrees and submodules
+// Blank when root is not a repo
+//
+// readRepoExcludesFileOption 通过 go-git 存储读取仓库配置中的 core.excludesFile
+// 存储同样能找到 .git 文件背后的配置，如链接工作树和子模块
+// root 不是仓库时返回空
+func readRepoExcludesFileOption(root string) (string, error) {
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
+	if err != nil {
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return "", nil
+		}
+		return "", erero.Wro(err)
+	}
+	cfg, err := repo.Storer.Config()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	return cfg.Raw.Section("core").Option("excludesfile"), nil
+}
+
+// xdgConfigHome returns $XDG_CONFIG_HOME, falling back to ~/.config, blank when home is unknown
+//
+// xdgConfigHome 返回 $XDG_CONFIG_HOME，未设置时回退到 ~/.config，无法确定主目录时返回空
+func xdgConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config")
+}
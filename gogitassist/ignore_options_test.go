@@ -0,0 +1,150 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// setupIgnoreOptionsRepo creates a temp repo with isolated HOME and XDG_CONFIG_HOME
+// Returns repo root and the XDG config DIR
+//
+// setupIgnoreOptionsRepo 创建具有隔离 HOME 和 XDG_CONFIG_HOME 的临时仓库
+// 返回仓库根目录和 XDG 配置目录
+func setupIgnoreOptionsRepo(t *testing.T) (string, string) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-ignore-options-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	root := filepath.Join(tempDIR, "repo")
+	configHome := filepath.Join(tempDIR, "xdg")
+	t.Setenv("HOME", filepath.Join(tempDIR, "home"))
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, name := range []string{"GIT_CONFIG_SYSTEM", "GIT_CONFIG_GLOBAL"} {
+		t.Setenv(name, "")
+		must.Done(os.Unsetenv(name))
+	}
+
+	rese.P1(gogitassist.InitRepo(root))
+	for _, name := range []string{"a.tmp", "b.bak", "c.txt"} {
+		writeIgnoreTestFile(root, name, "content\n")
+	}
+	return root, configHome
+}
+
+// statusPaths lists paths reported by worktree status using the given options
+//
+// statusPaths 列出使用给定选项时工作树状态报告的路径
+func statusPaths(t *testing.T, root string, options *gogitassist.IgnoreOptions) []string {
+	_, tree, err := gogitassist.NewRepoTreeWithIgnoreOptions(root, options)
+	require.NoError(t, err)
+	var paths []string
+	for path := range rese.V1(tree.Status()) {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// TestNewRepoTreeWithIgnoreOptions_DefaultExcludesFile verifies the XDG git/ignore default
+//
+// TestNewRepoTreeWithIgnoreOptions_DefaultExcludesFile 验证 XDG git/ignore 默认文件
+func TestNewRepoTreeWithIgnoreOptions_DefaultExcludesFile(t *testing.T) {
+	root, configHome := setupIgnoreOptionsRepo(t)
+	writeIgnoreTestFile(configHome, "git/ignore", "*.tmp\n")
+
+	require.ElementsMatch(t, []string{"b.bak", "c.txt"}, statusPaths(t, root, gogitassist.NewIgnoreOptions()))
+	require.ElementsMatch(t, []string{"a.tmp", "b.bak", "c.txt"}, statusPaths(t, root, gogitassist.NewIgnoreOptions().DefaultExcludesFile(false)))
+}
+
+// TestNewRepoTreeWithIgnoreOptions_LocalExcludesFile verifies repo config core.excludesFile replaces the default
+//
+// TestNewRepoTreeWithIgnoreOptions_LocalExcludesFile 验证仓库配置的 core.excludesFile 替代默认文件
+func TestNewRepoTreeWithIgnoreOptions_LocalExcludesFile(t *testing.T) {
+	root, configHome := setupIgnoreOptionsRepo(t)
+	writeIgnoreTestFile(configHome, "git/ignore", "*.tmp\n")
+	writeIgnoreTestFile(root, "ignore-rules", "*.bak\n")
+	configText := string(rese.V1(os.ReadFile(filepath.Join(root, ".git", "config"))))
+	writeIgnoreTestFile(root, ".git/config", configText+"[core]\n\texcludesFile = ignore-rules\n")
+
+	excludesFile, err := gogitassist.ResolveExcludesFile(root, gogitassist.NewIgnoreOptions())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "ignore-rules"), excludesFile)
+
+	require.ElementsMatch(t, []string{"a.tmp", "c.txt", "ignore-rules"}, statusPaths(t, root, gogitassist.NewIgnoreOptions()))
+	require.ElementsMatch(t, []string{"b.bak", "c.txt", "ignore-rules"}, statusPaths(t, root, gogitassist.NewIgnoreOptions().LocalConfig(false)))
+}
+
+// TestResolveExcludesFile_ConfigEnv verifies GIT_CONFIG_GLOBAL and GIT_CONFIG_SYSTEM choose the config files
+// GIT_CONFIG_GLOBAL takes the place of both user config files, GIT_CONFIG_NOSYSTEM still skips the system one
+//
+// TestResolveExcludesFile_ConfigEnv 验证 GIT_CONFIG_GLOBAL 和 GIT_CONFIG_SYSTEM 选择配置文件
+// GIT_CONFIG_GLOBAL 取代两个用户配置文件，GIT_CONFIG_NOSYSTEM 仍会跳过系统配置
+func TestResolveExcludesFile_ConfigEnv(t *testing.T) {
+	root, configHome := setupIgnoreOptionsRepo(t)
+	writeIgnoreTestFile(configHome, "git/config", "[core]\n\texcludesFile = xdg-rules\n")
+	writeIgnoreTestFile(root, "global.config", "[core]\n\texcludesFile = global-rules\n")
+	writeIgnoreTestFile(root, "system.config", "[core]\n\texcludesFile = system-rules\n")
+
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(root, "global.config"))
+	excludesFile, err := gogitassist.ResolveExcludesFile(root, gogitassist.NewIgnoreOptions())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "global-rules"), excludesFile)
+
+	t.Setenv("GIT_CONFIG_GLOBAL", "")
+	excludesFile, err = gogitassist.ResolveExcludesFile(root, gogitassist.NewIgnoreOptions())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(configHome, "git", "ignore"), excludesFile)
+
+	t.Setenv("GIT_CONFIG_SYSTEM", filepath.Join(root, "system.config"))
+	excludesFile, err = gogitassist.ResolveExcludesFile(root, gogitassist.NewIgnoreOptions())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(configHome, "git", "ignore"), excludesFile)
+
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "")
+	excludesFile, err = gogitassist.ResolveExcludesFile(root, gogitassist.NewIgnoreOptions())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "system-rules"), excludesFile)
+}
+
+// TestNewRepoTreeWithIgnoreOptions_Failure verifies load failures are returned
+//
+// TestNewRepoTreeWithIgnoreOptions_Failure 验证加载失败会被返回
+func TestNewRepoTreeWithIgnoreOptions_Failure(t *testing.T) {
+	root, configHome := setupIgnoreOptionsRepo(t)
+	writeIgnoreTestFile(configHome, "git/config", "[core\n")
+
+	_, _, err := gogitassist.NewRepoTreeWithIgnoreOptions(root, gogitassist.NewIgnoreOptions())
+	require.Error(t, err)
+
+	_, _, err = gogitassist.NewRepoTreeWithIgnoreOptions(root, gogitassist.NewIgnoreOptions().GlobalConfig(false))
+	require.NoError(t, err)
+}
+
+// TestNewRepoTreeWithIgnoreOptions_LinkedWorktree verifies core.excludesFile comes from the shared config
+// A linked worktree has a .git file, so the config must be found through the gitdir indirection
+//
+// TestNewRepoTreeWithIgnoreOptions_LinkedWorktree 验证 core.excludesFile 来自共享配置
+// 链接工作树的 .git 是文件，因此必须通过 gitdir 间接引用找到配置
+func TestNewRepoTreeWithIgnoreOptions_LinkedWorktree(t *testing.T) {
+	root, _ := setupIgnoreOptionsRepo(t)
+	repo := rese.P1(gogitassist.NewRepo(root))
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+	configText := string(rese.V1(os.ReadFile(filepath.Join(root, ".git", "config"))))
+	writeIgnoreTestFile(root, ".git/config", configText+"[core]\n\texcludesFile = ignore-rules\n")
+
+	worktreePath := addLinkedWorktree(root, "linked-tree", "linked")
+	writeIgnoreTestFile(worktreePath, "ignore-rules", "*.bak\n")
+	writeIgnoreTestFile(worktreePath, "d.bak", "content\n")
+
+	excludesFile, err := gogitassist.ResolveExcludesFile(worktreePath, gogitassist.NewIgnoreOptions())
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(worktreePath, "ignore-rules"), excludesFile)
+
+	require.ElementsMatch(t, []string{"ignore-rules"}, statusPaths(t, worktreePath, gogitassist.NewIgnoreOptions()))
+}
@@ -0,0 +1,184 @@
+package gogitassist
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/yyle88/erero"
+)
+
+// IgnoreRule is one gitignore pattern together with the place it comes from
+// Implements gitignore.Pattern so rules can go wherever patterns are accepted
+//
+// IgnoreRule 是一条 gitignore 模式及其来源位置
+// 实现了 gitignore.Pattern，因此规则可用于任何接受模式的地方
+type IgnoreRule struct {
+	Text    string            // Pattern text after trimming, "!" kept // 修剪后的模式文本，保留 "!"
+	Source  string            // File the rule comes from, blank when parsed from text // 规则来源文件，从文本解析时为空
+	Line    int               // Line number in the source, starting at 1 // 来源中的行号，从 1 开始
+	Domain  []string          // DIR components the rule is bound to // 规则绑定的目录组成部分
+	Negated bool              // Whether the rule re-includes paths // 规则是否重新包含路径
+	pattern gitignore.Pattern // Compiled pattern // 编译后的模式
+}
+
+// Match matches path components against the rule
+//
+// Match 将路径组成部分与规则进行匹配
+func (r *IgnoreRule) Match(path []string, isDir bool) gitignore.MatchResult {
+	return r.pattern.Match(path, isDir)
+}
+
+// String returns the rule in "source:line:pattern" form, as git check-ignore -v prints
+//
+// String 以 "source:line:pattern" 形式返回规则，与 git check-ignore -v 的输出一致
+func (r *IgnoreRule) String() string {
+	return r.Source + ":" + strconv.Itoa(r.Line) + ":" + r.Text
+}
+
+// ParseIgnoreRules parses gitignore text following gitignore(5)
+// Lines starting with "#" are comments, "\#" and "\!" start patterns with a literal "#" or "!"
+// Trailing spaces are dropped unless escaped with a backslash, leading spaces are kept
+// Handles CRLF line endings and a leading UTF-8 BOM
+//
+// ParseIgnoreRules 按照 gitignore(5) 解析 gitignore 文本
+// 以 "#" 开头的行是注释，"\#" 和 "\!" 表示以字面 "#" 或 "!" 开头的模式
+// 除非用反斜杠转义，否则尾随空格会被删除，前导空格会被保留
+// 支持 CRLF 换行符和开头的 UTF-8 BOM
+func ParseIgnoreRules(text string, source string, domain []string) []*IgnoreRule {
+	var rules = make([]*IgnoreRule, 0)
+	for idx, line := range strings.Split(strings.TrimPrefix(text, "\uFEFF"), "\n") {
+		line = trimTrailingSpaces(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rules = append(rules, &IgnoreRule{
+			Text:    line,
+			Source:  source,
+			Line:    idx + 1,
+			Domain:  append([]string(nil), domain...),
+			Negated: strings.HasPrefix(line, "!"),
+			pattern: gitignore.ParsePattern(line, domain),
+		})
+	}
+	return rules
+}
+
+// trimTrailingSpaces drops unescaped trailing spaces, the same way git does
+// A backslash keeps the next character, a trailing lone backslash keeps the line as is
+//
+// trimTrailingSpaces 删除未转义的尾随空格，与 git 的处理方式相同
+// 反斜杠保留其后的字符，行尾单独的反斜杠会使该行保持原样
+func trimTrailingSpaces(line string) string {
+	lastSpace := -1
+	for idx := 0; idx < len(line); idx++ {
+		switch line[idx] {
+		case ' ':
+			if lastSpace < 0 {
+				lastSpace = idx
+			}
+		case '\\':
+			idx++
+			if idx == len(line) {
+				return line
+			}
+			lastSpace = -1
+		default:
+			lastSpace = -1
+		}
+	}
+	if lastSpace >= 0 {
+		return line[:lastSpace]
+	}
+	return line
+}
+
+// IgnoreRulePatterns converts rules into patterns usable as worktree excludes
+//
+// IgnoreRulePatterns 将规则转换为可用作工作树排除规则的模式
+func IgnoreRulePatterns(rules []*IgnoreRule) []gitignore.Pattern {
+	var patterns = make([]gitignore.Pattern, 0, len(rules))
+	for _, rule := range rules {
+		patterns = append(patterns, rule)
+	}
+	return patterns
+}
+
+// IgnoreMatch reports the rule deciding whether a path is ignored
+//
+// IgnoreMatch 报告决定路径是否被忽略的规则
+type IgnoreMatch struct {
+	Path string      // Slash separated path relative to repo root // 相对于仓库根目录的斜杠分隔路径
+	Rule *IgnoreRule // Last matching rule, which wins // 最后匹配的规则，即生效的规则
+}
+
+// IsIgnored checks if the path is ignored, false when nothing matched or a negated rule won
+//
+// IsIgnored 检查路径是否被忽略，无匹配或取反规则生效时返回 false
+func (m *IgnoreMatch) IsIgnored() bool {
+	return m != nil && !m.Rule.Negated
+}
+
+// MatchIgnoreRules finds the last rule matching the path components
+// Returns nil when no rule matches
+//
+// MatchIgnoreRules 查找与路径组成部分匹配的最后一条规则
+// 没有规则匹配时返回 nil
+func MatchIgnoreRules(rules []*IgnoreRule, path []string, isDir bool) *IgnoreMatch {
+	for idx := len(rules) - 1; idx >= 0; idx-- {
+		if rules[idx].Match(path, isDir) != gitignore.NoMatch {
+			return &IgnoreMatch{Path: strings.Join(path, "/"), Rule: rules[idx]}
+		}
+	}
+	return nil
+}
+
+// CheckIgnore tells which rule decides whether the path is ignored, like git check-ignore -v
+// Finds the repo root by walking up from the path and loads rules of every layer
+// A rule ignoring a parent DIR wins, as git never re-includes files inside ignored DIRs
+// Returns nil match when no rule applies, check IsIgnored since negated rules also match
+//
+// CheckIgnore 判断哪条规则决定路径是否被忽略，类似 git check-ignore -v
+// 从路径向上查找仓库根目录并加载所有层的规则
+// 忽略父目录的规则优先，因为 git 不会重新包含被忽略目录中的文件
+// 无规则适用时返回 nil，由于取反规则也会匹配，请检查 IsIgnored
+func CheckIgnore(path string) (*IgnoreMatch, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	location, err := DiscoverRepo(absPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	root := location.Root
+	relativePath, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if relativePath == "." {
+		return nil, erero.Errorf("path %s is the repo root", path)
+	}
+	rules, err := LoadIgnoreRules(root, NewIgnoreOptions())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	info, err := os.Stat(absPath)
+	isDir := err == nil && info.IsDir()
+	return matchIgnoredPath(rules, strings.Split(filepath.ToSlash(relativePath), "/"), isDir), nil
+}
+
+// matchIgnoredPath matches path components checking parent DIRs first, as git does
+//
+// matchIgnoredPath 与 git 一样先检查父目录，再匹配路径组成部分
+func matchIgnoredPath(rules []*IgnoreRule, components []string, isDir bool) *IgnoreMatch {
+	for idx := 1; idx < len(components); idx++ {
+		if match := MatchIgnoreRules(rules, components[:idx], true); match.IsIgnored() {
+			return match
+		}
+	}
+	return MatchIgnoreRules(rules, components, isDir)
+}
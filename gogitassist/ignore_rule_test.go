@@ -0,0 +1,86 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestParseIgnoreRules verifies escapes, trailing spaces, comments and CRLF follow gitignore(5)
+//
+// TestParseIgnoreRules 验证转义、尾随空格、注释和 CRLF 遵循 gitignore(5)
+func TestParseIgnoreRules(t *testing.T) {
+	text := "\uFEFF# comment\r\n\\#hash\r\n\\!bang\r\ntrail   \r\nkeep\\ \r\nmix\\  \r\n  lead\r\n!neg\r\n\r\n"
+	rules := gogitassist.ParseIgnoreRules(text, ".gitignore", nil)
+
+	var texts []string
+	for _, rule := range rules {
+		texts = append(texts, rule.Text)
+	}
+	require.Equal(t, []string{"\\#hash", "\\!bang", "trail", "keep\\ ", "mix\\ ", "  lead", "!neg"}, texts)
+	require.Equal(t, 2, rules[0].Line)
+	require.Equal(t, ".gitignore:2:\\#hash", rules[0].String())
+	require.True(t, rules[6].Negated)
+	require.False(t, rules[1].Negated)
+
+	cases := map[string]bool{
+		"#hash":  true,
+		"!bang":  true,
+		"trail":  true,
+		"keep ":  true,
+		"keep":   false,
+		"mix ":   true,
+		"  lead": true,
+		"lead":   false,
+	}
+	for name, ignored := range cases {
+		require.Equal(t, ignored, gogitassist.MatchIgnoreRules(rules, []string{name}, false).IsIgnored(), name)
+	}
+	match := gogitassist.MatchIgnoreRules(rules, []string{"neg"}, false)
+	require.NotNil(t, match)
+	require.False(t, match.IsIgnored())
+}
+
+// TestCheckIgnore verifies reporting the deciding pattern, file and line
+// Should report the parent DIR rule when a file sits inside an ignored DIR
+//
+// TestCheckIgnore 验证报告决定性的模式、文件和行号
+// 当文件位于被忽略的目录中时应该报告父目录规则
+func TestCheckIgnore(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-check-ignore-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	rese.P1(gogitassist.InitRepo(tempDIR))
+	writeIgnoreTestFile(tempDIR, ".gitignore", "# generated\n*.log\nbuild/\n")
+	writeIgnoreTestFile(tempDIR, "sub/.gitignore", "!keep.log\n")
+	writeIgnoreTestFile(tempDIR, "build/keep.log", "content\n")
+
+	match, err := gogitassist.CheckIgnore(filepath.Join(tempDIR, "app.log"))
+	require.NoError(t, err)
+	require.True(t, match.IsIgnored())
+	require.Equal(t, filepath.Join(tempDIR, ".gitignore"), match.Rule.Source)
+	require.Equal(t, 2, match.Rule.Line)
+	require.Equal(t, "*.log", match.Rule.Text)
+
+	match, err = gogitassist.CheckIgnore(filepath.Join(tempDIR, "sub", "keep.log"))
+	require.NoError(t, err)
+	require.False(t, match.IsIgnored())
+	require.Equal(t, filepath.Join(tempDIR, "sub", ".gitignore"), match.Rule.Source)
+
+	match, err = gogitassist.CheckIgnore(filepath.Join(tempDIR, "build", "keep.log"))
+	require.NoError(t, err)
+	require.True(t, match.IsIgnored())
+	require.Equal(t, "build/", match.Rule.Text)
+	require.Equal(t, "build", match.Path)
+
+	match, err = gogitassist.CheckIgnore(filepath.Join(tempDIR, "main.go"))
+	require.NoError(t, err)
+	require.Nil(t, match)
+	require.False(t, match.IsIgnored())
+}
@@ -0,0 +1,330 @@
+package gogitassist
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	formatconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/yyle88/erero"
+)
+
+// InitOptions configures a one-call repo bootstrap through InitRepoWithOptions
+// Supports fluent configuration pattern for convenient setup
+//
+// InitOptions 配置通过 InitRepoWithOptions 一次完成的仓库初始化
+// 支持流畅配置模式以便于设置
+type InitOptions struct {
+	branch             string                    // Initial branch name, go-git default when blank // 初始分支名，为空时使用 go-git 默认值
+	bare               bool                      // Create a bare repo without worktree // 创建不带工作树的裸仓库
+	objectFormat       formatconfig.ObjectFormat // Object hash format, sha1 when blank // 对象哈希格式，为空时为 sha1
+	templateDIR        string                    // DIR copied into the git DIR // 复制到 git 目录中的目录
+	userName           string                    // Config user.name // 配置的 user.name
+	userMailbox        string                    // Config user.email // 配置的 user.email
+	remotes            []remoteSetting           // Remotes to create in order // 按顺序创建的远程
+	gitignoreTemplates []string                  // Built-in gitignore templates to write // 要写入的内置 gitignore 模板
+	commitMessage      string                    // Initial commit message, no commit when blank // 初始提交消息，为空时不提交
+}
+
+// remoteSetting is a remote name with its URL
+//
+// remoteSetting 是远程名称及其 URL
+type remoteSetting struct {
+	name      string // Remote name // 远程名称
+	remoteURL string // Remote URL // 远程 URL
+}
+
+// NewInitOptions creates options for a plain non-bare repo without extra setup
+//
+// NewInitOptions 创建普通非裸仓库且无额外设置的选项
+func NewInitOptions() *InitOptions {
+	return &InitOptions{}
+}
+
+// Branch sets the initial branch name like "main" and returns updated InitOptions
+//
+// Branch 设置初始分支名（如 "main"）并返回更新的 InitOptions
+func (o *InitOptions) Branch(branch string) *InitOptions {
+	o.branch = branch
+	return o
+}
+
+// Bare sets whether the repo has no worktree and returns updated InitOptions
+//
+// Bare 设置仓库是否没有工作树并返回更新的 InitOptions
+func (o *InitOptions) Bare(bare bool) *InitOptions {
+	o.bare = bare
+	return o
+}
+
+// ObjectFormat sets the object hash format, "sha1" or "sha256", and returns updated InitOptions
+// A sha256 repo needs go-git built with sha256 support, otherwise init fails
+//
+// ObjectFormat 设置对象哈希格式（"sha1" 或 "sha256"）并返回更新的 InitOptions
+// sha256 仓库需要启用 sha256 支持构建的 go-git，否则初始化失败
+func (o *InitOptions) ObjectFormat(objectFormat string) *InitOptions {
+	o.objectFormat = formatconfig.ObjectFormat(objectFormat)
+	return o
+}
+
+// TemplateDIR sets a DIR whose contents get copied into the git DIR and returns updated InitOptions
+// Like git init --template, files already created by init are kept
+//
+// TemplateDIR 设置其内容会被复制到 git 目录中的目录并返回更新的 InitOptions
+// 与 git init --template 一样，保留初始化已创建的文件
+func (o *InitOptions) TemplateDIR(templateDIR string) *InitOptions {
+	o.templateDIR = templateDIR
+	return o
+}
+
+// UserInfo sets user.name and user.email in repo config and returns updated InitOptions
+// Also used as the author of the initial commit
+//
+// UserInfo 设置仓库配置中的 user.name 和 user.email 并返回更新的 InitOptions
+// 同时用作初始提交的作者
+func (o *InitOptions) UserInfo(username string, mailbox string) *InitOptions {
+	o.userName = username
+	o.userMailbox = mailbox
+	return o
+}
+
+// Remote appends a remote to create and returns updated InitOptions
+//
+// Remote 追加要创建的远程并返回更新的 InitOptions
+func (o *InitOptions) Remote(name string, remoteURL string) *InitOptions {
+	o.remotes = append(o.remotes, remoteSetting{name: name, remoteURL: remoteURL})
+	return o
+}
+
+// GitignoreTemplates sets built-in gitignore templates written into .gitignore and returns updated InitOptions
+//
+// GitignoreTemplates 设置写入 .gitignore 的内置 gitignore 模板并返回更新的 InitOptions
+func (o *InitOptions) GitignoreTemplates(names ...string) *InitOptions {
+	o.gitignoreTemplates = names
+	return o
+}
+
+// InitialCommit sets the message of a first commit holding every file in the worktree and returns updated InitOptions
+// The commit is created even when the worktree is blank, bare repos cannot have it
+// The author comes from UserInfo, else from user config, init fails when neither gives one
+//
+// InitialCommit 设置包含工作树中所有文件的首次提交的消息并返回更新的 InitOptions
+// 即使工作树为空也会创建提交，裸仓库不支持此选项
+// 作者来自 UserInfo，否则来自用户配置，两者都未提供时初始化失败
+func (o *InitOptions) InitialCommit(message string) *InitOptions {
+	o.commitMessage = message
+	return o
+}
+
+// InitRepoWithOptions initializes a new Git repo at path and applies the configured setup
+// Steps run in order: init, template copy, config, remotes, .gitignore, initial commit
+// Remotes and template names are checked first, when a later step fails the files it created are removed
+//
+// InitRepoWithOptions 在指定路径初始化新的 Git 仓库并应用配置的设置
+// 步骤按顺序执行：初始化、复制模板、配置、远程、.gitignore、初始提交
+// 先检查远程和模板名称，后续步骤失败时删除其创建的文件
+func InitRepoWithOptions(path string, options *InitOptions) (*git.Repository, error) {
+	if options.bare && (options.commitMessage != "" || len(options.gitignoreTemplates) > 0) {
+		return nil, erero.New("bare repo has no worktree for initial commit or gitignore")
+	}
+	// Check names before creating anything, so a typo leaves no half-made repo
+	// 在创建任何内容之前检查名称，避免拼写错误留下半成品仓库
+	if _, err := ComposeGitignoreTemplates(options.gitignoreTemplates...); err != nil {
+		return nil, erero.Wro(err)
+	}
+	if err := validateRemoteSettings(options.remotes); err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Note what is at path already, so cleanup removes just what this call created
+	// 记录 path 中已有的内容，使清理时仅删除本次调用创建的内容
+	existingNames, err := readExistingNames(path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	repo, err := initRepoSetup(path, options)
+	if err != nil {
+		if cleanupErr := removeCreatedNames(path, existingNames); cleanupErr != nil {
+			return nil, erero.Wrapf(err, "cleanup failed: %v", cleanupErr)
+		}
+		return nil, erero.Wro(err)
+	}
+	return repo, nil
+}
+
+// initRepoSetup runs the init steps of InitRepoWithOptions after the options are checked
+//
+// initRepoSetup 在选项检查通过后执行 InitRepoWithOptions 的初始化步骤
+func initRepoSetup(path string, options *InitOptions) (*git.Repository, error) {
+	var initOptions = &git.PlainInitOptions{Bare: options.bare, ObjectFormat: options.objectFormat}
+	if options.branch != "" {
+		initOptions.DefaultBranch = plumbing.NewBranchReferenceName(options.branch)
+	}
+	repo, err := git.PlainInitWithOptions(path, initOptions)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	if options.templateDIR != "" {
+		gitDIR := path
+		if !options.bare {
+			gitDIR = filepath.Join(path, git.GitDirName)
+		}
+		if err := copyTemplateDIR(options.templateDIR, gitDIR); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if options.userName != "" || options.userMailbox != "" {
+		if err := SetConfigUserInfo(repo, options.userName, options.userMailbox); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	for _, remote := range options.remotes {
+		if err := AddRemote(repo, remote.name, remote.remoteURL); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if len(options.gitignoreTemplates) > 0 {
+		if err := MergeGitignoreTemplates(path, options.gitignoreTemplates...); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if options.commitMessage != "" {
+		if err := commitInitialFiles(repo, options); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	return repo, nil
+}
+
+// validateRemoteSettings checks remote names and URLs the way go-git does, and rejects repeated names
+//
+// validateRemoteSettings 按 go-git 的方式检查远程名称和 URL，并拒绝重复的名称
+func validateRemoteSettings(remotes []remoteSetting) error {
+	var names = map[string]bool{}
+	for _, remote := range remotes {
+		remoteConfig := &config.RemoteConfig{Name: remote.name, URLs: []string{remote.remoteURL}}
+		if err := remoteConfig.Validate(); err != nil {
+			return erero.Wrapf(err, "wrong remote %q", remote.name)
+		}
+		if remote.remoteURL == "" {
+			return erero.Errorf("remote %q has blank URL", remote.name)
+		}
+		if names[remote.name] {
+			return erero.Errorf("remote %q is repeated", remote.name)
+		}
+		names[remote.name] = true
+	}
+	return nil
+}
+
+// readExistingNames lists the entry names in path, nil when path does not exist yet
+//
+// readExistingNames 列出 path 中的条目名称，path 尚不存在时返回 nil
+func readExistingNames(path string) (map[string]bool, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	var names = make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	return names, nil
+}
+
+// removeCreatedNames removes path when it did not exist before, else the entries not in existingNames
+//
+// removeCreatedNames 在 path 原本不存在时删除 path，否则删除不在 existingNames 中的条目
+func removeCreatedNames(path string, existingNames map[string]bool) error {
+	if existingNames == nil {
+		if err := os.RemoveAll(path); err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	for _, entry := range entries {
+		if existingNames[entry.Name()] {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(path, entry.Name())); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// copyTemplateDIR copies files of the template DIR into the git DIR, keeping existing files and modes
+//
+// copyTemplateDIR 将模板目录中的文件复制到 git 目录，保留已存在的文件和文件模式
+func copyTemplateDIR(templateDIR string, gitDIR string) error {
+	err := filepath.WalkDir(templateDIR, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return erero.Wro(err)
+		}
+		relativePath, err := filepath.Rel(templateDIR, path)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		target := filepath.Join(gitDIR, relativePath)
+		info, err := entry.Info()
+		if err != nil {
+			return erero.Wro(err)
+		}
+		if entry.IsDir() {
+			if err := os.MkdirAll(target, info.Mode().Perm()); err != nil {
+				return erero.Wro(err)
+			}
+			return nil
+		}
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		if err := os.WriteFile(target, data, info.Mode().Perm()); err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// commitInitialFiles stages every file and creates the initial commit, allowing a blank one
+// go-git takes the author from user.name and user.email of repo, user or system config,
+// failing with git.ErrMissingAuthor when none sets both
+//
+// commitInitialFiles 暂存所有文件并创建初始提交，允许空提交
+// go-git 从仓库、用户或系统配置的 user.name 和 user.email 获取作者，
+// 都未同时设置两者时以 git.ErrMissingAuthor 失败
+func commitInitialFiles(repo *git.Repository, options *InitOptions) error {
+	tree, err := repo.Worktree()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if err := tree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return erero.Wro(err)
+	}
+	_, err = tree.Commit(options.commitMessage, &git.CommitOptions{
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
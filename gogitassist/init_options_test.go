@@ -0,0 +1,163 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestInitRepoWithOptions verifies branch, config, remotes, gitignore and the initial commit in one call
+//
+// TestInitRepoWithOptions 验证一次调用完成分支、配置、远程、gitignore 和初始提交
+func TestInitRepoWithOptions(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-init-options-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, "main.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "app.exe", "binary\n")
+
+	options := gogitassist.NewInitOptions().
+		Branch("main").
+		UserInfo("Test Account", "test@example.com").
+		Remote("origin", "https://github.com/example/repo.git").
+		Remote("backup", "https://gitlab.com/example/repo.git").
+		GitignoreTemplates("go").
+		InitialCommit("Initial commit")
+	repo, err := gogitassist.InitRepoWithOptions(tempDIR, options)
+	require.NoError(t, err)
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+	require.Equal(t, plumbing.NewBranchReferenceName("main"), head.Name())
+
+	commit, err := repo.CommitObject(head.Hash())
+	require.NoError(t, err)
+	require.Equal(t, "Initial commit", commit.Message)
+	require.Equal(t, "Test Account", commit.Author.Name)
+
+	var names []string
+	idx := rese.P1(repo.Storer.Index())
+	for _, entry := range idx.Entries {
+		names = append(names, entry.Name)
+	}
+	require.Equal(t, []string{".gitignore", "main.go"}, names)
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	require.Equal(t, "test@example.com", cfg.User.Email)
+	require.Equal(t, []string{"https://github.com/example/repo.git"}, cfg.Remotes["origin"].URLs)
+	require.Equal(t, []string{"https://gitlab.com/example/repo.git"}, cfg.Remotes["backup"].URLs)
+}
+
+// TestInitRepoWithOptions_BareTemplate verifies a bare repo with template files copied into it
+// Should keep files created by init and reject an initial commit
+//
+// TestInitRepoWithOptions_BareTemplate 验证复制了模板文件的裸仓库
+// 应该保留初始化创建的文件并拒绝初始提交
+func TestInitRepoWithOptions_BareTemplate(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-init-options-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	templateDIR := filepath.Join(tempDIR, "template")
+	writeIgnoreTestFile(templateDIR, "hooks/pre-commit", "#!/bin/sh\nexit 0\n")
+	writeIgnoreTestFile(templateDIR, "description", "Template repo\n")
+	writeIgnoreTestFile(templateDIR, "HEAD", "ref: refs/heads/template\n")
+	must.Done(os.Chmod(filepath.Join(templateDIR, "hooks/pre-commit"), 0755))
+
+	repoDIR := filepath.Join(tempDIR, "repo.git")
+	repo, err := gogitassist.InitRepoWithOptions(repoDIR, gogitassist.NewInitOptions().
+		Branch("trunk").
+		Bare(true).
+		TemplateDIR(templateDIR))
+	require.NoError(t, err)
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+	require.True(t, cfg.Core.IsBare)
+
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	require.NoError(t, err)
+	require.Equal(t, plumbing.NewBranchReferenceName("trunk"), head.Target())
+
+	info, err := os.Stat(filepath.Join(repoDIR, "hooks/pre-commit"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	require.Equal(t, "Template repo\n", string(rese.V1(os.ReadFile(filepath.Join(repoDIR, "description")))))
+
+	_, err = gogitassist.InitRepoWithOptions(filepath.Join(tempDIR, "other.git"), gogitassist.NewInitOptions().
+		Bare(true).
+		InitialCommit("Initial commit"))
+	require.Error(t, err)
+	require.NoDirExists(t, filepath.Join(tempDIR, "other.git"))
+}
+
+// TestInitRepoWithOptions_CommitAuthor verifies the initial commit takes its author from user config
+// Should fail instead of committing with a blank author when no config gives one
+//
+// TestInitRepoWithOptions_CommitAuthor 验证初始提交从用户配置获取作者
+// 没有配置提供作者时应该失败，而不是以空作者提交
+func TestInitRepoWithOptions_CommitAuthor(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-init-options-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	home := filepath.Join(tempDIR, "home")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDIR, "xdg"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	_, err := gogitassist.InitRepoWithOptions(filepath.Join(tempDIR, "anonymous"), gogitassist.NewInitOptions().InitialCommit("Initial commit"))
+	require.ErrorIs(t, err, git.ErrMissingAuthor)
+	require.NoDirExists(t, filepath.Join(tempDIR, "anonymous"))
+
+	writeIgnoreTestFile(home, ".gitconfig", "[user]\n\tname = Config Account\n\temail = config@example.com\n")
+	repo, err := gogitassist.InitRepoWithOptions(filepath.Join(tempDIR, "configured"), gogitassist.NewInitOptions().InitialCommit("Initial commit"))
+	require.NoError(t, err)
+	commit := rese.P1(repo.CommitObject(rese.P1(repo.Head()).Hash()))
+	require.Equal(t, "Config Account", commit.Author.Name)
+	require.Equal(t, "config@example.com", commit.Author.Email)
+}
+
+// TestInitRepoWithOptions_Cleanup verifies a failed init leaves no half-made repo behind
+// Should reject bad remotes before creating anything, and remove just its own files on later failures
+//
+// TestInitRepoWithOptions_Cleanup 验证初始化失败时不留下半成品仓库
+// 应该在创建任何内容之前拒绝错误的远程，并在后续失败时仅删除自己创建的文件
+func TestInitRepoWithOptions_Cleanup(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-init-options-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	t.Setenv("HOME", filepath.Join(tempDIR, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDIR, "xdg"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	for _, options := range []*gogitassist.InitOptions{
+		gogitassist.NewInitOptions().Remote("origin", ""),
+		gogitassist.NewInitOptions().Remote("", "https://github.com/go-xlan/gogit.git"),
+		gogitassist.NewInitOptions().Remote("origin", "https://github.com/go-xlan/gogit.git").Remote("origin", "https://github.com/go-xlan/other.git"),
+	} {
+		_, err := gogitassist.InitRepoWithOptions(filepath.Join(tempDIR, "remotes"), options)
+		require.Error(t, err)
+		require.NoDirExists(t, filepath.Join(tempDIR, "remotes"))
+	}
+
+	// The initial commit fails for want of an author, files present before init are kept
+	// 初始提交因缺少作者而失败，初始化之前已有的文件被保留
+	projectDIR := filepath.Join(tempDIR, "project")
+	writeIgnoreTestFile(projectDIR, "main.go", "package main\n")
+	_, err := gogitassist.InitRepoWithOptions(projectDIR, gogitassist.NewInitOptions().GitignoreTemplates("go").InitialCommit("Initial commit"))
+	require.ErrorIs(t, err, git.ErrMissingAuthor)
+	require.FileExists(t, filepath.Join(projectDIR, "main.go"))
+	require.NoDirExists(t, filepath.Join(projectDIR, ".git"))
+	require.NoFileExists(t, filepath.Join(projectDIR, ".gitignore"))
+}
@@ -31,7 +31,7 @@ func InitRepo(path string) (*git.Repository, error) {
 // SetConfigUserName 设置仓库配置中的 user.name
 // 配置此仓库中用于提交署名的名称
 func SetConfigUserName(repo *git.Repository, name string) error {
-	cfg, err := repo.Config()
+	cfg, err := loadRepoConfig(repo)
 	if err != nil {
 		return erero.Wro(err)
 	}
@@ -48,7 +48,7 @@ func SetConfigUserName(repo *git.Repository, name string) error {
 // SetConfigUserMailbox 在仓库配置中设置 user.email 属性
 // 配置此仓库中用于提交署名的邮箱
 func SetConfigUserMailbox(repo *git.Repository, mailbox string) error {
-	cfg, err := repo.Config()
+	cfg, err := loadRepoConfig(repo)
 	if err != nil {
 		return erero.Wro(err)
 	}
@@ -65,7 +65,7 @@ func SetConfigUserMailbox(repo *git.Repository, mailbox string) error {
 // SetConfigUserInfo 在仓库配置中设置 user.name 和 user.email 属性
 // 配置此仓库中创建提交时使用的署名信息
 func SetConfigUserInfo(repo *git.Repository, username, mailbox string) error {
-	cfg, err := repo.Config()
+	cfg, err := loadRepoConfig(repo)
 	if err != nil {
 		return erero.Wro(err)
 	}
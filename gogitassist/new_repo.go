@@ -8,10 +8,7 @@
 package gogitassist
 
 import (
-	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
-	"github.com/yyle88/done"
 	"github.com/yyle88/erero"
 	"github.com/yyle88/zaplog"
 	"go.uber.org/zap"
@@ -19,13 +16,15 @@ import (
 
 // NewRepo opens an existing Git repo at the specified root path
 // Returns configured repo instance that is usable with Git commands
-// Wraps go-git PlainOpen with exception handling
+// Wraps go-git PlainOpen with exception handling, linked worktrees included
+// Use OpenRepo to start from a subDIR
 //
 // NewRepo 在指定根路径打开现有 Git 仓库
 // 返回可用于 Git 命令的配置好的仓库实例
-// 使用异常处理包装 go-git PlainOpen
+// 使用异常处理包装 go-git PlainOpen，包括链接工作树
+// 如需从子目录开始请使用 OpenRepo
 func NewRepo(root string) (*git.Repository, error) {
-	repo, err := git.PlainOpen(root)
+	repo, err := git.PlainOpenWithOptions(root, &git.PlainOpenOptions{EnableDotGitCommonDir: true})
 	if err != nil {
 		return nil, erero.Wro(err)
 	}
@@ -33,37 +32,19 @@ func NewRepo(root string) (*git.Repository, error) {
 }
 
 // NewRepoTreeWithIgnore creates repo and worktree with comprehensive ignore pattern support
-// Loads and applies ignore patterns from system, system, and project levels
+// Loads and applies ignore patterns from core.excludesFile, info/exclude and .gitignore files
 // Returns both repo and worktree instances configured with ignore patterns
+// Load failures are returned, see NewRepoTreeWithIgnoreOptions to choose layers
 //
 // NewRepoTreeWithIgnore 创建带全面忽略模式支持的仓库和工作树
-// 从系统、全局和项目级别加载并应用忽略模式
+// 从 core.excludesFile、info/exclude 和 .gitignore 文件加载并应用忽略模式
 // 返回配置了忽略模式的仓库和工作树实例
+// 加载失败会被返回，如需选择层请参见 NewRepoTreeWithIgnoreOptions
 func NewRepoTreeWithIgnore(root string) (repo *git.Repository, tree *git.Worktree, err error) {
-	repo, err = NewRepo(root)
+	repo, tree, err = NewRepoTreeWithIgnoreOptions(root, NewIgnoreOptions())
 	if err != nil {
 		return nil, nil, erero.Wro(err)
 	}
-	tree, err = repo.Worktree()
-	if err != nil {
-		return nil, nil, erero.Wro(err)
-	}
-
-	// Apply ignore patterns in sequence: system < global < project
-	// 按优先级应用忽略模式：系统 < 全局 < 项目
-
-	// Lowest sequence - system-wide ignore patterns
-	// 最低优先级 - 系统级忽略模式
-	SetIgnorePatterns(tree, done.VAE(gitignore.LoadSystemPatterns(osfs.New("/"))).Done())
-
-	// Medium sequence - global ignore patterns
-	// 中等优先级 - 全局忽略模式
-	SetIgnorePatterns(tree, done.VAE(gitignore.LoadGlobalPatterns(osfs.New("/"))).Done())
-
-	// Highest sequence - project-specific ignore patterns
-	// 最高优先级 - 项目特定忽略模式
-	SetIgnorePatterns(tree, done.VAE(LoadProjectIgnorePatterns(root)).Done())
-
 	return repo, tree, nil
 }
 
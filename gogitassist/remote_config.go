@@ -0,0 +1,327 @@
+package gogitassist
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	formatconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/yyle88/erero"
+)
+
+// RemoteInfo describes one remote with all its configured URLs
+//
+// RemoteInfo 描述一个远程及其配置的全部 URL
+type RemoteInfo struct {
+	Name          string   // Remote name // 远程名称
+	FetchURLs     []string // URLs from remote.<name>.url // 来自 remote.<name>.url 的 URL
+	PushURLs      []string // URLs from remote.<name>.pushurl, blank means push goes to FetchURLs // 来自 remote.<name>.pushurl 的 URL，为空表示推送到 FetchURLs
+	FetchRefSpecs []string // Refspecs from remote.<name>.fetch // 来自 remote.<name>.fetch 的 refspec
+}
+
+// ListRemotes returns the remotes of the repo in config file order
+// Fetch URLs and push URLs are kept apart, unlike go-git remote config
+//
+// ListRemotes 按配置文件顺序返回仓库的远程
+// 与 go-git 远程配置不同，拉取 URL 和推送 URL 分开保存
+func ListRemotes(repo *git.Repository) ([]*RemoteInfo, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var remotes []*RemoteInfo
+	for _, subsection := range cfg.Raw.Section("remote").Subsections {
+		if _, ok := cfg.Remotes[subsection.Name]; !ok {
+			continue
+		}
+		remotes = append(remotes, &RemoteInfo{
+			Name:          subsection.Name,
+			FetchURLs:     subsection.Options.GetAll("url"),
+			PushURLs:      subsection.Options.GetAll("pushurl"),
+			FetchRefSpecs: subsection.Options.GetAll("fetch"),
+		})
+	}
+	return remotes, nil
+}
+
+// GetRemote returns the info of the named remote
+// Returns error when the remote is not found
+//
+// GetRemote 返回指定远程的信息
+// 未找到远程时返回错误
+func GetRemote(repo *git.Repository, name string) (*RemoteInfo, error) {
+	remotes, err := ListRemotes(repo)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, remote := range remotes {
+		if remote.Name == name {
+			return remote, nil
+		}
+	}
+	return nil, erero.Wrapf(git.ErrRemoteNotFound, "remote %q", name)
+}
+
+// GetRemoteFetchURL returns the first fetch URL of the named remote
+//
+// GetRemoteFetchURL 返回指定远程的第一个拉取 URL
+func GetRemoteFetchURL(repo *git.Repository, name string) (string, error) {
+	remote, err := GetRemote(repo, name)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	if len(remote.FetchURLs) == 0 {
+		return "", erero.New("remote has no URLs configured")
+	}
+	return remote.FetchURLs[0], nil
+}
+
+// GetRemotePushURL returns the first push URL of the named remote
+// Falls back to the first fetch URL when no push URL is set, as git push does
+//
+// GetRemotePushURL 返回指定远程的第一个推送 URL
+// 未设置推送 URL 时与 git push 一样回退到第一个拉取 URL
+func GetRemotePushURL(repo *git.Repository, name string) (string, error) {
+	remote, err := GetRemote(repo, name)
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	if len(remote.PushURLs) > 0 {
+		return remote.PushURLs[0], nil
+	}
+	if len(remote.FetchURLs) == 0 {
+		return "", erero.New("remote has no URLs configured")
+	}
+	return remote.FetchURLs[0], nil
+}
+
+// SetRemoteURLs replaces the fetch URLs of the named remote
+// Needs at least one URL, push URLs are kept
+//
+// SetRemoteURLs 替换指定远程的拉取 URL
+// 至少需要一个 URL，推送 URL 保持不变
+func SetRemoteURLs(repo *git.Repository, name string, urls ...string) error {
+	if len(urls) == 0 {
+		return erero.Wro(config.ErrRemoteConfigEmptyURL)
+	}
+	cfg, err := loadRepoConfig(repo)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	remote, ok := cfg.Remotes[name]
+	if !ok {
+		return erero.Wrapf(git.ErrRemoteNotFound, "remote %q", name)
+	}
+	remote.URLs = slices.Clone(urls)
+	if err := repo.SetConfig(cfg); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// AddRemoteURL appends one fetch URL to the named remote, skipping it when present
+//
+// AddRemoteURL 向指定远程追加一个拉取 URL，已存在时跳过
+func AddRemoteURL(repo *git.Repository, name string, remoteURL string) error {
+	remote, err := GetRemote(repo, name)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if slices.Contains(remote.FetchURLs, remoteURL) {
+		return nil
+	}
+	if err := SetRemoteURLs(repo, name, append(remote.FetchURLs, remoteURL)...); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// SetRemotePushURLs replaces the push URLs of the named remote
+// Passing no URLs removes them, so push goes back to the fetch URLs
+//
+// SetRemotePushURLs 替换指定远程的推送 URL
+// 不传 URL 时删除推送 URL，使推送回到拉取 URL
+func SetRemotePushURLs(repo *git.Repository, name string, urls ...string) error {
+	cfg, err := loadRepoConfig(repo)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if _, ok := cfg.Remotes[name]; !ok {
+		return erero.Wrapf(git.ErrRemoteNotFound, "remote %q", name)
+	}
+	subsection := cfg.Raw.Section("remote").Subsection(name)
+	if len(urls) == 0 {
+		subsection.RemoveOption("pushurl")
+	} else {
+		subsection.SetOption("pushurl", urls...)
+	}
+	if err := repo.SetConfig(cfg); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// SetRemoteFetchRefSpecs replaces the fetch refspecs of the named remote
+// Each refspec is validated, passing none restores the default +refs/heads/*:refs/remotes/<name>/*
+//
+// SetRemoteFetchRefSpecs 替换指定远程的拉取 refspec
+// 每个 refspec 都会被校验，不传时恢复默认的 +refs/heads/*:refs/remotes/<name>/*
+func SetRemoteFetchRefSpecs(repo *git.Repository, name string, refSpecs ...string) error {
+	var fetch = make([]config.RefSpec, 0, len(refSpecs))
+	for _, refSpec := range refSpecs {
+		spec := config.RefSpec(refSpec)
+		if err := spec.Validate(); err != nil {
+			return erero.Wrapf(err, "wrong refspec %q", refSpec)
+		}
+		fetch = append(fetch, spec)
+	}
+	cfg, err := loadRepoConfig(repo)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	remote, ok := cfg.Remotes[name]
+	if !ok {
+		return erero.Wrapf(git.ErrRemoteNotFound, "remote %q", name)
+	}
+	remote.Fetch = fetch
+	if err := repo.SetConfig(cfg); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// RenameRemote renames a remote, like git remote rename
+// Moves the config in place, rewrites refspecs and branch tracking pointing at it,
+// and moves the remote-tracking refs to refs/remotes/<newName>/
+//
+// RenameRemote 重命名远程，类似 git remote rename
+// 原位移动配置，改写指向它的 refspec 和分支跟踪配置，
+// 并将远程跟踪引用移动到 refs/remotes/<newName>/
+func RenameRemote(repo *git.Repository, oldName string, newName string) error {
+	if err := plumbing.NewRemoteHEADReferenceName(newName).Validate(); err != nil {
+		return erero.Wrapf(err, "wrong remote name %q", newName)
+	}
+	cfg, err := loadRepoConfig(repo)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	remote, ok := cfg.Remotes[oldName]
+	if !ok {
+		return erero.Wrapf(git.ErrRemoteNotFound, "remote %q", oldName)
+	}
+	if oldName == newName {
+		return nil
+	}
+	if _, ok := cfg.Remotes[newName]; ok {
+		return erero.Wrapf(git.ErrRemoteExists, "remote %q", newName)
+	}
+
+	// Rename the raw subsection first so the remote keeps its place in the file
+	// 先重命名原始子节，使远程保持在文件中的位置
+	cfg.Raw.Section("remote").Subsection(oldName).Name = newName
+	delete(cfg.Remotes, oldName)
+	remote.Name = newName
+	cfg.Remotes[newName] = remote
+	oldPrefix := "refs/remotes/" + oldName + "/"
+	newPrefix := "refs/remotes/" + newName + "/"
+	for idx, spec := range remote.Fetch {
+		source, target, ok := strings.Cut(spec.String(), ":")
+		if ok && strings.HasPrefix(target, oldPrefix) {
+			remote.Fetch[idx] = config.RefSpec(source + ":" + newPrefix + strings.TrimPrefix(target, oldPrefix))
+		}
+	}
+	for _, branch := range cfg.Branches {
+		if branch.Remote == oldName {
+			branch.Remote = newName
+		}
+	}
+	if err := repo.SetConfig(cfg); err != nil {
+		return erero.Wro(err)
+	}
+
+	if err := renameRemoteRefs(repo, oldPrefix, newPrefix); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// renameRemoteRefs moves refs under oldPrefix to newPrefix, symbolic targets included
+//
+// renameRemoteRefs 将 oldPrefix 下的引用移动到 newPrefix，包括符号引用的目标
+func renameRemoteRefs(repo *git.Repository, oldPrefix string, newPrefix string) error {
+	iter, err := repo.Storer.IterReferences()
+	if err != nil {
+		return erero.Wro(err)
+	}
+	var refs []*plumbing.Reference
+	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+		if strings.HasPrefix(ref.Name().String(), oldPrefix) {
+			refs = append(refs, ref)
+		}
+		return nil
+	}); err != nil {
+		return erero.Wro(err)
+	}
+
+	renameRef := func(name plumbing.ReferenceName) plumbing.ReferenceName {
+		if rest, ok := strings.CutPrefix(name.String(), oldPrefix); ok {
+			return plumbing.ReferenceName(newPrefix + rest)
+		}
+		return name
+	}
+	for _, ref := range refs {
+		var moved *plumbing.Reference
+		if ref.Type() == plumbing.SymbolicReference {
+			moved = plumbing.NewSymbolicReference(renameRef(ref.Name()), renameRef(ref.Target()))
+		} else {
+			moved = plumbing.NewHashReference(renameRef(ref.Name()), ref.Hash())
+		}
+		if err := repo.Storer.SetReference(moved); err != nil {
+			return erero.Wro(err)
+		}
+		if err := repo.Storer.RemoveReference(ref.Name()); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// loadRepoConfig loads the repo config with remote URLs holding just remote.<name>.url
+// go-git folds pushurl values into remote URLs when loading, and after an insteadOf rule applies
+// it saves the URLs from before the rule, push URLs included, so either way saving the config back
+// would copy the push URLs into url. Here each remote is rebuilt from its raw section alone,
+// so no insteadOf state is left and the push URLs stay in the raw section
+//
+// loadRepoConfig 加载仓库配置，其中远程 URL 仅包含 remote.<name>.url
+// go-git 加载时会将 pushurl 的值并入远程 URL，应用 insteadOf 规则后又会保存规则应用前的 URL，
+// 其中同样包含推送 URL，因此无论哪种情况，保存回去都会把推送 URL 复制到 url 中。
+// 这里仅根据原始配置节重建每个远程，不留下 insteadOf 状态，推送 URL 仅保留在原始配置节中
+func loadRepoConfig(repo *git.Repository) (*config.Config, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if !cfg.Raw.HasSection("remote") {
+		return cfg, nil
+	}
+
+	rawRemotes := formatconfig.New()
+	rawRemotes.Sections = formatconfig.Sections{cfg.Raw.Section("remote")}
+	var buffer bytes.Buffer
+	if err := formatconfig.NewEncoder(&buffer).Encode(rawRemotes); err != nil {
+		return nil, erero.Wro(err)
+	}
+	rebuilt := config.NewConfig()
+	if err := rebuilt.Unmarshal(buffer.Bytes()); err != nil {
+		return nil, erero.Wro(err)
+	}
+	for name, remote := range rebuilt.Remotes {
+		remote.URLs = rebuilt.Raw.Section("remote").Subsection(name).Options.GetAll("url")
+	}
+	cfg.Remotes = rebuilt.Remotes
+	cfg.Raw.Section("remote").Subsections = rebuilt.Raw.Section("remote").Subsections
+	return cfg, nil
+}
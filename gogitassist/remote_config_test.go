@@ -0,0 +1,167 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestSetRemoteURLs verifies replacing fetch URLs and push URLs kept apart
+// Push URLs must survive later config writes without leaking into url
+//
+// TestSetRemoteURLs 验证替换拉取 URL 并与推送 URL 分开保存
+// 推送 URL 在后续配置写入后必须保留，且不能混入 url
+func TestSetRemoteURLs(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-remote-config-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	require.NoError(t, gogitassist.AddRemote(repo, "origin", "https://github.com/example/repo.git"))
+
+	require.NoError(t, gogitassist.SetRemotePushURLs(repo, "origin", "git@github.com:example/repo.git"))
+	require.NoError(t, gogitassist.AddRemoteURL(repo, "origin", "https://mirror.example.com/repo.git"))
+	require.NoError(t, gogitassist.SetConfigUserInfo(repo, "Test Account", "test@example.com"))
+
+	remote, err := gogitassist.GetRemote(repo, "origin")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://github.com/example/repo.git", "https://mirror.example.com/repo.git"}, remote.FetchURLs)
+	require.Equal(t, []string{"git@github.com:example/repo.git"}, remote.PushURLs)
+
+	require.Equal(t, "https://github.com/example/repo.git", rese.V1(gogitassist.GetRemoteFetchURL(repo, "origin")))
+	require.Equal(t, "git@github.com:example/repo.git", rese.V1(gogitassist.GetRemotePushURL(repo, "origin")))
+
+	require.NoError(t, gogitassist.SetRemoteURLs(repo, "origin", "https://gitlab.com/example/repo.git"))
+	require.NoError(t, gogitassist.SetRemotePushURLs(repo, "origin"))
+	remote, err = gogitassist.GetRemote(repo, "origin")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://gitlab.com/example/repo.git"}, remote.FetchURLs)
+	require.Empty(t, remote.PushURLs)
+	require.Equal(t, "https://gitlab.com/example/repo.git", rese.V1(gogitassist.GetRemotePushURL(repo, "origin")))
+
+	require.Error(t, gogitassist.SetRemoteURLs(repo, "origin"))
+	require.Error(t, gogitassist.SetRemoteURLs(repo, "upstream", "https://github.com/example/repo.git"))
+	_, err = gogitassist.GetRemote(repo, "upstream")
+	require.Error(t, err)
+}
+
+// TestSetRemoteFetchRefSpecs verifies custom refspecs, validation and restoring the default
+//
+// TestSetRemoteFetchRefSpecs 验证自定义 refspec、校验以及恢复默认值
+func TestSetRemoteFetchRefSpecs(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-remote-config-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	require.NoError(t, gogitassist.AddRemote(repo, "origin", "https://github.com/example/repo.git"))
+
+	refSpecs := []string{"+refs/heads/main:refs/remotes/origin/main", "+refs/pull/*/head:refs/remotes/origin/pr/*"}
+	require.NoError(t, gogitassist.SetRemoteFetchRefSpecs(repo, "origin", refSpecs...))
+	remote := rese.P1(gogitassist.GetRemote(repo, "origin"))
+	require.Equal(t, refSpecs, remote.FetchRefSpecs)
+
+	require.Error(t, gogitassist.SetRemoteFetchRefSpecs(repo, "origin", "refs/heads/*:refs/remotes/origin/main"))
+
+	require.NoError(t, gogitassist.SetRemoteFetchRefSpecs(repo, "origin"))
+	remote = rese.P1(gogitassist.GetRemote(repo, "origin"))
+	require.Equal(t, []string{"+refs/heads/*:refs/remotes/origin/*"}, remote.FetchRefSpecs)
+}
+
+// TestRenameRemote verifies renaming moves config, refspecs, branch tracking and remote refs
+//
+// TestRenameRemote 验证重命名会移动配置、refspec、分支跟踪和远程引用
+func TestRenameRemote(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-remote-config-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, "main.go", "package main\n")
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	hash := rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+	require.NoError(t, gogitassist.AddRemote(repo, "origin", "https://github.com/example/repo.git"))
+	require.NoError(t, gogitassist.AddRemote(repo, "backup", "https://backup.example.com/repo.git"))
+
+	cfg := rese.P1(repo.Config())
+	cfg.Branches["main"] = &config.Branch{Name: "main", Remote: "origin", Merge: plumbing.NewBranchReferenceName("main")}
+	require.NoError(t, repo.SetConfig(cfg))
+	require.NoError(t, gogitassist.SetRemotePushURLs(repo, "origin", "git@github.com:example/repo.git"))
+	must.Done(repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "main"), hash)))
+	must.Done(repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.NewRemoteHEADReferenceName("origin"), plumbing.NewRemoteReferenceName("origin", "main"))))
+
+	require.NoError(t, gogitassist.RenameRemote(repo, "origin", "upstream"))
+
+	remotes := rese.V1(gogitassist.ListRemotes(repo))
+	require.Len(t, remotes, 2)
+	require.Equal(t, "upstream", remotes[0].Name)
+	require.Equal(t, []string{"https://github.com/example/repo.git"}, remotes[0].FetchURLs)
+	require.Equal(t, []string{"git@github.com:example/repo.git"}, remotes[0].PushURLs)
+	require.Equal(t, []string{"+refs/heads/*:refs/remotes/upstream/*"}, remotes[0].FetchRefSpecs)
+	require.Equal(t, "backup", remotes[1].Name)
+
+	cfg = rese.P1(repo.Config())
+	require.Equal(t, "upstream", cfg.Branches["main"].Remote)
+
+	ref := rese.P1(repo.Reference(plumbing.NewRemoteReferenceName("upstream", "main"), true))
+	require.Equal(t, hash, ref.Hash())
+	ref = rese.P1(repo.Reference(plumbing.NewRemoteHEADReferenceName("upstream"), false))
+	require.Equal(t, plumbing.NewRemoteReferenceName("upstream", "main"), ref.Target())
+	_, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", "main"), true)
+	require.Error(t, err)
+	require.NoFileExists(t, filepath.Join(tempDIR, ".git/refs/remotes/origin/main"))
+
+	require.Error(t, gogitassist.RenameRemote(repo, "upstream", "backup"))
+	require.Error(t, gogitassist.RenameRemote(repo, "origin", "other"))
+}
+
+// TestSetRemoteURLs_InsteadOf verifies config writers keep url and pushurl apart under an insteadOf rule
+// go-git saves the URLs from before the rule, push URLs included, unless each remote is rebuilt
+//
+// TestSetRemoteURLs_InsteadOf 验证在 insteadOf 规则下配置写入保持 url 与 pushurl 分开
+// 除非重建每个远程，否则 go-git 会保存规则应用前的 URL，其中包含推送 URL
+func TestSetRemoteURLs_InsteadOf(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-remote-config-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, "main.go", "package main\n")
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+	configText := string(rese.V1(os.ReadFile(filepath.Join(tempDIR, ".git", "config"))))
+	writeIgnoreTestFile(tempDIR, ".git/config", configText+`[url "https://github.com/"]
+	insteadOf = gh:
+[remote "origin"]
+	url = gh:example/repo.git
+	pushurl = git@github.com:example/repo.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+`)
+
+	requireRemoteURLs := func(name string, fetchURLs []string) {
+		remote, err := gogitassist.GetRemote(repo, name)
+		require.NoError(t, err)
+		require.Equal(t, fetchURLs, remote.FetchURLs)
+		require.Equal(t, []string{"git@github.com:example/repo.git"}, remote.PushURLs)
+	}
+
+	require.NoError(t, gogitassist.SetRemoteURLs(repo, "origin", "gh:example/other.git"))
+	requireRemoteURLs("origin", []string{"gh:example/other.git"})
+
+	require.NoError(t, gogitassist.SetRemoteFetchRefSpecs(repo, "origin", "+refs/heads/main:refs/remotes/origin/main"))
+	require.NoError(t, gogitassist.SetConfigUserInfo(repo, "Test Account", "test@example.com"))
+	require.NoError(t, gogitassist.SetUpstream(repo, rese.P1(repo.Head()).Name().Short(), "origin", "main"))
+	requireRemoteURLs("origin", []string{"gh:example/other.git"})
+
+	require.NoError(t, gogitassist.RenameRemote(repo, "origin", "upstream"))
+	requireRemoteURLs("upstream", []string{"gh:example/other.git"})
+
+	cfg := rese.P1(repo.Config())
+	require.Equal(t, []string{"https://github.com/example/other.git", "git@github.com:example/repo.git"}, cfg.Remotes["upstream"].URLs)
+	require.Contains(t, cfg.URLs, "https://github.com/")
+}
@@ -0,0 +1,321 @@
+package gogitassist
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+)
+
+// RemoteURL is a parsed remote URL
+// Handles scp-like SSH (git@host:org/repo.git), ssh://, git://, http(s)://, file:// and local paths
+//
+// RemoteURL 是解析后的远程 URL
+// 支持 scp 风格 SSH（git@host:org/repo.git）、ssh://、git://、http(s)://、file:// 以及本地路径
+type RemoteURL struct {
+	Scheme  string // One of ssh, git, http, https, file // ssh、git、http、https、file 之一
+	User    string // User name, blank when not given // 用户名，未给出时为空
+	Host    string // Host name, blank with file // 主机名，file 时为空
+	Port    int    // Port number, 0 when not given // 端口号，未给出时为 0
+	Path    string // Repo path, without leading slash unless file or an absolute scp-like path // 仓库路径，除 file 或 scp 风格绝对路径外不带前导斜杠
+	Owner   string // Path before the repo name, may hold subgroups like group/sub // 仓库名之前的路径，可包含 group/sub 这样的子组
+	Repo    string // Repo name without .git suffix // 不带 .git 后缀的仓库名
+	SCPLike bool   // Written as user@host:path // 以 user@host:path 形式书写
+	Local   bool   // Written as a plain path without scheme // 以不带协议的普通路径书写
+}
+
+// scpLikeRegexp matches user@host:path, the user part being optional
+//
+// scpLikeRegexp 匹配 user@host:path，其中用户部分可选
+var scpLikeRegexp = regexp.MustCompile(`^(?:([^@/]+)@)?([^@/:]+):(.*)$`)
+
+// ParseRemoteURL parses a remote URL as git understands it
+// A colon before any slash marks the scp-like SSH form, like git does,
+// other strings without scheme are local paths
+// An scp-like path keeps its leading slash, since host:/srv/repo is absolute while host:srv/repo is home-relative
+//
+// ParseRemoteURL 按照 git 的规则解析远程 URL
+// 与 git 一样，斜杠之前出现冒号表示 scp 风格 SSH 形式，
+// 其他不带协议的字符串为本地路径
+// scp 风格路径保留前导斜杠，因为 host:/srv/repo 是绝对路径，而 host:srv/repo 相对于主目录
+func ParseRemoteURL(remoteURL string) (*RemoteURL, error) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	if remoteURL == "" {
+		return nil, erero.New("blank remote URL")
+	}
+	if strings.Contains(remoteURL, "://") {
+		return parseSchemeURL(remoteURL)
+	}
+	if matches := scpLikeRegexp.FindStringSubmatch(remoteURL); matches != nil && !isWindowsDrive(remoteURL) {
+		if matches[3] == "" {
+			return nil, erero.Errorf("wrong remote URL %q, path is blank", remoteURL)
+		}
+		res := &RemoteURL{
+			Scheme:  "ssh",
+			User:    matches[1],
+			Host:    matches[2],
+			Path:    matches[3],
+			SCPLike: true,
+		}
+		res.fillOwnerRepo()
+		return res, nil
+	}
+	res := &RemoteURL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(filepath.Clean(remoteURL)),
+		Local:  true,
+	}
+	res.fillOwnerRepo()
+	return res, nil
+}
+
+// parseSchemeURL parses the scheme://[user@]host[:port]/path form
+//
+// parseSchemeURL 解析 scheme://[user@]host[:port]/path 形式
+func parseSchemeURL(remoteURL string) (*RemoteURL, error) {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var scheme = strings.ToLower(parsed.Scheme)
+	switch scheme {
+	case "ssh", "git+ssh", "ssh+git":
+		scheme = "ssh"
+	case "git", "http", "https", "file":
+	default:
+		return nil, erero.Errorf("wrong remote URL %q, scheme %q is not supported", remoteURL, parsed.Scheme)
+	}
+
+	res := &RemoteURL{
+		Scheme: scheme,
+		Host:   parsed.Hostname(),
+		Path:   parsed.Path,
+	}
+	if parsed.User != nil {
+		res.User = parsed.User.Username()
+	}
+	if port := parsed.Port(); port != "" {
+		if res.Port, err = strconv.Atoi(port); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	if scheme != "file" {
+		if res.Host == "" {
+			return nil, erero.Errorf("wrong remote URL %q, host is blank", remoteURL)
+		}
+		res.Path = strings.TrimPrefix(res.Path, "/")
+	}
+	res.fillOwnerRepo()
+	return res, nil
+}
+
+// isWindowsDrive checks if the string starts with a drive letter like C:\ or C:/
+//
+// isWindowsDrive 检查字符串是否以 C:\ 或 C:/ 这样的盘符开头
+func isWindowsDrive(remoteURL string) bool {
+	return len(remoteURL) >= 3 && remoteURL[1] == ':' && (remoteURL[2] == '\\' || remoteURL[2] == '/') &&
+		('a' <= remoteURL[0] && remoteURL[0] <= 'z' || 'A' <= remoteURL[0] && remoteURL[0] <= 'Z')
+}
+
+// fillOwnerRepo splits Path into Owner and Repo
+//
+// fillOwnerRepo 将 Path 拆分为 Owner 和 Repo
+func (u *RemoteURL) fillOwnerRepo() {
+	repoPath := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	u.Repo = path.Base(repoPath)
+	if u.Repo == "." || u.Repo == "/" {
+		u.Repo = ""
+	}
+	if u.Scheme != "file" {
+		repoPath = strings.TrimPrefix(repoPath, "/")
+		if idx := strings.LastIndex(repoPath, "/"); idx > 0 {
+			u.Owner = repoPath[:idx]
+		}
+	}
+}
+
+// String formats the URL back in the form it was written in
+//
+// String 按照原有的书写形式格式化 URL
+func (u *RemoteURL) String() string {
+	if u.Local {
+		return filepath.FromSlash(u.Path)
+	}
+	var userPrefix string
+	if u.User != "" {
+		userPrefix = u.User + "@"
+	}
+	if u.SCPLike {
+		return userPrefix + u.Host + ":" + u.Path
+	}
+	host := u.Host
+	if u.Port != 0 {
+		host += ":" + strconv.Itoa(u.Port)
+	}
+	if u.Scheme == "file" {
+		return "file://" + host + u.Path
+	}
+	return u.Scheme + "://" + userPrefix + host + "/" + strings.TrimPrefix(u.Path, "/")
+}
+
+// IsLocal checks if the URL points at a repo on the local filesystem
+//
+// IsLocal 检查 URL 是否指向本地文件系统上的仓库
+func (u *RemoteURL) IsLocal() bool {
+	return u.Scheme == "file"
+}
+
+// ToHTTPS returns the https:// form of the URL, dropping the user
+// The port of an https URL is kept, a custom port of other schemes says nothing about HTTPS,
+// so it gives an error instead of a URL pointing at the wrong port
+// Returns error with local URLs
+//
+// ToHTTPS 返回 URL 的 https:// 形式，去掉用户
+// https URL 的端口会保留，其他协议的自定义端口无法说明 HTTPS 的端口，
+// 因此返回错误，而不是返回指向错误端口的 URL
+// 本地 URL 返回错误
+func (u *RemoteURL) ToHTTPS() (*RemoteURL, error) {
+	if u.IsLocal() {
+		return nil, erero.Errorf("local remote URL %q has no HTTPS form", u.String())
+	}
+	if u.Port != 0 && u.Scheme != "https" {
+		return nil, erero.Errorf("remote URL %q has custom port %d, its HTTPS port is unknown", u.String(), u.Port)
+	}
+	return &RemoteURL{
+		Scheme: "https",
+		Host:   u.Host,
+		Port:   u.Port,
+		Path:   strings.TrimPrefix(u.Path, "/"),
+		Owner:  u.Owner,
+		Repo:   u.Repo,
+	}, nil
+}
+
+// ToSSH returns the scp-like SSH form of the URL, like git@host:org/repo.git
+// Keeps the SSH user when present, else uses git
+// The port of an ssh URL is kept, giving the ssh:// form since scp-like URLs have no port,
+// a custom port of other schemes says nothing about SSH, so it gives an error
+// Returns error with local URLs
+//
+// ToSSH 返回 URL 的 scp 风格 SSH 形式，如 git@host:org/repo.git
+// 存在 SSH 用户时保留，否则使用 git
+// ssh URL 的端口会保留，由于 scp 风格 URL 无法携带端口，此时返回 ssh:// 形式，
+// 其他协议的自定义端口无法说明 SSH 的端口，因此返回错误
+// 本地 URL 返回错误
+func (u *RemoteURL) ToSSH() (*RemoteURL, error) {
+	if u.IsLocal() {
+		return nil, erero.Errorf("local remote URL %q has no SSH form", u.String())
+	}
+	if u.Port != 0 && u.Scheme != "ssh" {
+		return nil, erero.Errorf("remote URL %q has custom port %d, its SSH port is unknown", u.String(), u.Port)
+	}
+	var user = "git"
+	if u.Scheme == "ssh" && u.User != "" {
+		user = u.User
+	}
+	return &RemoteURL{
+		Scheme:  "ssh",
+		User:    user,
+		Host:    u.Host,
+		Port:    u.Port,
+		Path:    u.Path,
+		Owner:   u.Owner,
+		Repo:    u.Repo,
+		SCPLike: u.Port == 0,
+	}, nil
+}
+
+// WebURL returns the web page of the repo, like https://github.com/org/repo
+// Returns error with local URLs
+//
+// WebURL 返回仓库的网页地址，如 https://github.com/org/repo
+// 本地 URL 返回错误
+func (u *RemoteURL) WebURL() (string, error) {
+	if u.IsLocal() {
+		return "", erero.Errorf("local remote URL %q has no web page", u.String())
+	}
+	if u.Repo == "" {
+		return "", erero.Errorf("remote URL %q has no repo name", u.String())
+	}
+	repoPath := u.Repo
+	if u.Owner != "" {
+		repoPath = u.Owner + "/" + u.Repo
+	}
+	host := u.Host
+	if u.Scheme == "https" && u.Port != 0 {
+		host += ":" + strconv.Itoa(u.Port)
+	}
+	return "https://" + host + "/" + repoPath, nil
+}
+
+// CommitURL returns the web page of a commit, see hostingLayout about the page layout
+//
+// CommitURL 返回某个提交的网页地址，页面布局参见 hostingLayout
+func (u *RemoteURL) CommitURL(hash string) (string, error) {
+	webURL, err := u.WebURL()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	commitPath, _ := hostingLayout(u.Host)
+	return webURL + commitPath + hash, nil
+}
+
+// BranchURL returns the web page of a branch, escaping each part of the branch name
+// See hostingLayout about the page layout
+//
+// BranchURL 返回某个分支的网页地址，分支名的每一段都会被转义
+// 页面布局参见 hostingLayout
+func (u *RemoteURL) BranchURL(branch string) (string, error) {
+	webURL, err := u.WebURL()
+	if err != nil {
+		return "", erero.Wro(err)
+	}
+	parts := strings.Split(branch, "/")
+	for idx, part := range parts {
+		parts[idx] = url.PathEscape(part)
+	}
+	_, branchPath := hostingLayout(u.Host)
+	return webURL + branchPath + strings.Join(parts, "/"), nil
+}
+
+// hostingLayout returns the path segments leading to commit and branch pages below the repo page
+// gitlab.com and bitbucket.org use their own layouts, matched by exact host,
+// every other host gets the GitHub layout, which Gitea and Gogs share
+//
+// hostingLayout 返回仓库页面之下通往提交页面和分支页面的路径段
+// gitlab.com 和 bitbucket.org 使用各自的布局，按主机名精确匹配，
+// 其他主机均使用 GitHub 布局，Gitea 和 Gogs 也采用该布局
+func hostingLayout(host string) (string, string) {
+	switch strings.ToLower(host) {
+	case "gitlab.com":
+		return "/-/commit/", "/-/tree/"
+	case "bitbucket.org":
+		return "/commits/", "/src/"
+	default:
+		return "/commit/", "/tree/"
+	}
+}
+
+// SameRepo checks if both URLs point at the same repo, whatever the form
+// Compares host case-insensitively and the path without .git suffix,
+// remote paths also without leading slash, since hosting sites serve both forms as one repo
+//
+// SameRepo 检查两个 URL 是否指向同一个仓库，不论书写形式
+// 主机名比较不区分大小写，路径比较时去掉 .git 后缀，
+// 远程路径还会去掉前导斜杠，因为托管网站将两种形式视为同一仓库
+func (u *RemoteURL) SameRepo(other *RemoteURL) bool {
+	if u.IsLocal() != other.IsLocal() || !strings.EqualFold(u.Host, other.Host) {
+		return false
+	}
+	trimPath := func(value string) string {
+		return strings.TrimSuffix(strings.TrimSuffix(value, "/"), ".git")
+	}
+	if !u.IsLocal() {
+		return trimPath(strings.TrimPrefix(u.Path, "/")) == trimPath(strings.TrimPrefix(other.Path, "/"))
+	}
+	return trimPath(u.Path) == trimPath(other.Path)
+}
@@ -0,0 +1,92 @@
+package gogitassist_test
+
+import (
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestParseRemoteURL verifies parsing each supported form and formatting it back
+//
+// TestParseRemoteURL 验证解析每种支持的形式并能格式化回原样
+func TestParseRemoteURL(t *testing.T) {
+	type expected struct {
+		scheme string
+		user   string
+		host   string
+		port   int
+		owner  string
+		repo   string
+	}
+	for remoteURL, want := range map[string]expected{
+		"git@github.com:go-xlan/gogit.git":                {"ssh", "git", "github.com", 0, "go-xlan", "gogit"},
+		"git@example.com:/srv/git/project.git":            {"ssh", "git", "example.com", 0, "srv/git", "project"},
+		"github.com:go-xlan/gogit":                        {"ssh", "", "github.com", 0, "go-xlan", "gogit"},
+		"ssh://git@gitlab.com:2222/group/sub/project.git": {"ssh", "git", "gitlab.com", 2222, "group/sub", "project"},
+		"https://github.com/go-xlan/gogit":                {"https", "", "github.com", 0, "go-xlan", "gogit"},
+		"https://token@github.com/go-xlan/gogit.git":      {"https", "token", "github.com", 0, "go-xlan", "gogit"},
+		"git://example.com/project.git":                   {"git", "", "example.com", 0, "", "project"},
+		"file:///srv/git/project.git":                     {"file", "", "", 0, "", "project"},
+		"/srv/git/project.git":                            {"file", "", "", 0, "", "project"},
+		"../project":                                      {"file", "", "", 0, "", "project"},
+	} {
+		res, err := gogitassist.ParseRemoteURL(remoteURL)
+		require.NoError(t, err, remoteURL)
+		t.Log(neatjsons.S(res))
+		require.Equal(t, want.scheme, res.Scheme, remoteURL)
+		require.Equal(t, want.user, res.User, remoteURL)
+		require.Equal(t, want.host, res.Host, remoteURL)
+		require.Equal(t, want.port, res.Port, remoteURL)
+		require.Equal(t, want.owner, res.Owner, remoteURL)
+		require.Equal(t, want.repo, res.Repo, remoteURL)
+		require.Equal(t, remoteURL, res.String())
+	}
+
+	for _, remoteURL := range []string{"", "ftp://example.com/repo.git", "https:///repo.git", "git@github.com:"} {
+		_, err := gogitassist.ParseRemoteURL(remoteURL)
+		require.Error(t, err, remoteURL)
+	}
+}
+
+// TestRemoteURL_Convert verifies SSH and HTTPS conversion and web URLs
+//
+// TestRemoteURL_Convert 验证 SSH 与 HTTPS 互相转换以及网页 URL
+func TestRemoteURL_Convert(t *testing.T) {
+	sshURL := rese.P1(gogitassist.ParseRemoteURL("git@github.com:go-xlan/gogit.git"))
+	httpsURL := rese.P1(sshURL.ToHTTPS())
+	require.Equal(t, "https://github.com/go-xlan/gogit.git", httpsURL.String())
+	require.Equal(t, "git@github.com:go-xlan/gogit.git", rese.P1(httpsURL.ToSSH()).String())
+	require.True(t, sshURL.SameRepo(rese.P1(gogitassist.ParseRemoteURL("https://GitHub.com/go-xlan/gogit"))))
+	require.False(t, sshURL.SameRepo(rese.P1(gogitassist.ParseRemoteURL("https://github.com/go-xlan/other"))))
+
+	require.Equal(t, "https://github.com/go-xlan/gogit", rese.V1(sshURL.WebURL()))
+	require.Equal(t, "https://github.com/go-xlan/gogit/commit/abc123", rese.V1(sshURL.CommitURL("abc123")))
+	require.Equal(t, "https://github.com/go-xlan/gogit/tree/feature/a%20b", rese.V1(sshURL.BranchURL("feature/a b")))
+
+	gitlabURL := rese.P1(gogitassist.ParseRemoteURL("ssh://git@gitlab.com:2222/group/sub/project.git"))
+	require.Equal(t, "ssh://git@gitlab.com:2222/group/sub/project.git", rese.P1(gitlabURL.ToSSH()).String())
+	_, err := gitlabURL.ToHTTPS()
+	require.Error(t, err)
+	require.Equal(t, "https://gitlab.com/group/sub/project/-/commit/abc123", rese.V1(gitlabURL.CommitURL("abc123")))
+	require.Equal(t, "https://gitlab.com/group/sub/project/-/tree/main", rese.V1(gitlabURL.BranchURL("main")))
+
+	selfHostedURL := rese.P1(gogitassist.ParseRemoteURL("https://notgitlab.example.com:8443/team/app.git"))
+	require.Equal(t, "https://notgitlab.example.com:8443/team/app.git", rese.P1(selfHostedURL.ToHTTPS()).String())
+	_, err = selfHostedURL.ToSSH()
+	require.Error(t, err)
+	require.Equal(t, "https://notgitlab.example.com:8443/team/app/commit/abc123", rese.V1(selfHostedURL.CommitURL("abc123")))
+
+	absoluteURL := rese.P1(gogitassist.ParseRemoteURL("git@example.com:/srv/git/project.git"))
+	require.Equal(t, "https://example.com/srv/git/project.git", rese.P1(absoluteURL.ToHTTPS()).String())
+	require.Equal(t, "git@example.com:/srv/git/project.git", rese.P1(absoluteURL.ToSSH()).String())
+
+	localURL := rese.P1(gogitassist.ParseRemoteURL("/srv/git/project.git"))
+	require.True(t, localURL.IsLocal())
+	_, err = localURL.ToSSH()
+	require.Error(t, err)
+	_, err = localURL.WebURL()
+	require.Error(t, err)
+}
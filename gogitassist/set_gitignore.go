@@ -3,7 +3,7 @@ package gogitassist
 import (
 	"os"
 	"path/filepath"
-	"strings"
+	"slices"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
@@ -22,22 +22,81 @@ func SetIgnorePatterns(worktree *git.Worktree, patterns []gitignore.Pattern) {
 	worktree.Excludes = append(worktree.Excludes, patterns...)
 }
 
-// LoadProjectIgnorePatterns loads gitignore patterns from project .gitignore file
-// Checks if root path exists and loads patterns from .gitignore file
-// Returns blank patterns if no .gitignore file found
+// LoadProjectIgnorePatterns loads gitignore patterns of the whole project
+// Reads .git/info/exclude, the root .gitignore and the .gitignore files in subdirectories
+// Patterns of nested files are bound to their own DIR, deeper files take precedence
+// Returns patterns in ascending precedence, blank when root does not exist
 //
-// LoadProjectIgnorePatterns 从项目 .gitignore 文件加载 gitignore 模式
-// 检查根路径是否存在并从 .gitignore 文件加载模式
-// 如果未找到 .gitignore 文件则返回空模式
+// LoadProjectIgnorePatterns 加载整个项目的 gitignore 模式
+// 读取 .git/info/exclude、根 .gitignore 以及子目录中的 .gitignore 文件
+// 嵌套文件的模式绑定到其所在目录，更深层的文件优先
+// 按优先级升序返回模式，根路径不存在时返回空模式
 func LoadProjectIgnorePatterns(root string) ([]gitignore.Pattern, error) {
+	rules, err := LoadProjectIgnoreRules(root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return IgnoreRulePatterns(rules), nil
+}
+
+// LoadProjectIgnoreRules loads the gitignore rules of the whole project with their sources
+// Same files and precedence as LoadProjectIgnorePatterns
+//
+// LoadProjectIgnoreRules 加载整个项目的 gitignore 规则及其来源
+// 文件和优先级与 LoadProjectIgnorePatterns 相同
+func LoadProjectIgnoreRules(root string) ([]*IgnoreRule, error) {
 	if osomitexist.IsRoot(root) {
-		patterns, err := LoadIgnorePatternsFromPath(filepath.Join(root, ".gitignore"))
+		_, commonDIR, err := resolveGitDIRs(root)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		rules, err := LoadIgnoreRulesFromPath(filepath.Join(commonDIR, "info", "exclude"), nil)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		nestedRules, err := loadNestedIgnoreRules(root, nil, rules)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return append(rules, nestedRules...), nil
+	}
+	return []*IgnoreRule{}, nil
+}
+
+// loadNestedIgnoreRules reads the .gitignore of DIR and then those of its subdirectories
+// Skips the .git DIR and subdirectories already ignored, like git does
+// Receives the rules loaded so far to decide which subdirectories are ignored
+//
+// loadNestedIgnoreRules 读取目录的 .gitignore，再读取其子目录中的 .gitignore
+// 与 git 一样跳过 .git 目录以及已被忽略的子目录
+// 接收目前已加载的规则，用于判断哪些子目录被忽略
+func loadNestedIgnoreRules(root string, domain []string, loaded []*IgnoreRule) ([]*IgnoreRule, error) {
+	dir := filepath.Join(append([]string{root}, domain...)...)
+	rules, err := LoadIgnoreRulesFromPath(filepath.Join(dir, ".gitignore"), domain)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == ".git" {
+			continue
+		}
+		current := append(slices.Clip(loaded), rules...)
+		subDomain := append(slices.Clip(domain), entry.Name())
+		if MatchIgnoreRules(current, subDomain, true).IsIgnored() {
+			continue
+		}
+		subRules, err := loadNestedIgnoreRules(root, subDomain, current)
 		if err != nil {
 			return nil, erero.Wro(err)
 		}
-		return patterns, nil
+		rules = append(rules, subRules...)
 	}
-	return []gitignore.Pattern{}, nil
+	return rules, nil
 }
 
 // LoadIgnorePatternsFromPath loads ignore patterns from specified file path
@@ -48,33 +107,66 @@ func LoadProjectIgnorePatterns(root string) ([]gitignore.Pattern, error) {
 // 读取文件内容并解析 gitignore 模式
 // 如果文件不存在则返回空模式
 func LoadIgnorePatternsFromPath(path string) ([]gitignore.Pattern, error) {
+	patterns, err := LoadIgnorePatternsFromPathWithDomain(path, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return patterns, nil
+}
+
+// LoadIgnorePatternsFromPathWithDomain loads ignore patterns bound to the given domain
+// Domain is the path components of the DIR holding the file, relative to the repo root
+// Returns blank patterns if file does not exist
+//
+// LoadIgnorePatternsFromPathWithDomain 加载绑定到给定作用域的忽略模式
+// 作用域是文件所在目录相对于仓库根目录的路径组成部分
+// 如果文件不存在则返回空模式
+func LoadIgnorePatternsFromPathWithDomain(path string, domain []string) ([]gitignore.Pattern, error) {
+	rules, err := LoadIgnoreRulesFromPath(path, domain)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return IgnoreRulePatterns(rules), nil
+}
+
+// LoadIgnoreRulesFromPath loads ignore rules bound to the given domain from a file
+// Each rule remembers the file path and line number it comes from
+// Returns blank rules if file does not exist
+//
+// LoadIgnoreRulesFromPath 从文件加载绑定到给定作用域的忽略规则
+// 每条规则记录其来源的文件路径和行号
+// 如果文件不存在则返回空规则
+func LoadIgnoreRulesFromPath(path string, domain []string) ([]*IgnoreRule, error) {
 	if osomitexist.IsFile(path) {
 		data, err := os.ReadFile(path)
 		if err != nil {
 			return nil, erero.Wro(err)
 		}
-		patterns, err := LoadIgnorePatternsFromText(string(data))
-		if err != nil {
-			return nil, erero.Wro(err)
-		}
-		return patterns, nil
+		return ParseIgnoreRules(string(data), path, domain), nil
 	}
-	return []gitignore.Pattern{}, nil
+	return []*IgnoreRule{}, nil
 }
 
 // LoadIgnorePatternsFromText parses gitignore patterns from text content
-// Processes each line, ignoring comments and blank lines
+// Follows gitignore(5), see ParseIgnoreRules about comments, escapes and trailing spaces
 // Returns parsed gitignore patterns that are usable
 //
 // LoadIgnorePatternsFromText 从文本内容解析 gitignore 模式
-// 处理每一行，忽略注释和空行
+// 遵循 gitignore(5)，关于注释、转义和尾随空格参见 ParseIgnoreRules
 // 返回可用的已解析 gitignore 模式
 func LoadIgnorePatternsFromText(text string) ([]gitignore.Pattern, error) {
-	var patterns = make([]gitignore.Pattern, 0)
-	for _, stx := range strings.Split(text, "\n") {
-		if stx = strings.TrimSpace(stx); stx != "" && !strings.HasPrefix(stx, "#") {
-			patterns = append(patterns, gitignore.ParsePattern(stx, []string{}))
-		}
+	patterns, err := LoadIgnorePatternsFromTextWithDomain(text, nil)
+	if err != nil {
+		return nil, erero.Wro(err)
 	}
 	return patterns, nil
 }
+
+// LoadIgnorePatternsFromTextWithDomain parses gitignore patterns bound to the given domain
+// Patterns match just paths inside the domain DIR, anchored ones relative to it
+//
+// LoadIgnorePatternsFromTextWithDomain 解析绑定到给定作用域的 gitignore 模式
+// 模式仅匹配作用域目录内的路径，锚定的模式相对于该目录
+func LoadIgnorePatternsFromTextWithDomain(text string, domain []string) ([]gitignore.Pattern, error) {
+	return IgnoreRulePatterns(ParseIgnoreRules(text, "", domain)), nil
+}
@@ -1,10 +1,15 @@
 package gogitassist_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
 	"github.com/yyle88/runpath"
 )
 
@@ -37,3 +42,56 @@ func TestLoadIgnorePatternsFromPath(t *testing.T) {
 
 	t.Log(len(patterns))
 }
+
+// writeIgnoreTestFile writes content to a file under root, creating parent DIRs
+//
+// writeIgnoreTestFile 将内容写入 root 下的文件，并创建父目录
+func writeIgnoreTestFile(root string, name string, content string) {
+	path := filepath.Join(root, name)
+	must.Done(os.MkdirAll(filepath.Dir(path), 0755))
+	must.Done(os.WriteFile(path, []byte(content), 0644))
+}
+
+// TestLoadProjectIgnorePatterns_Nested verifies nested .gitignore files and info/exclude
+// Should bind nested patterns to their DIR and let deeper files override parents
+//
+// TestLoadProjectIgnorePatterns_Nested 验证嵌套的 .gitignore 文件和 info/exclude
+// 应该将嵌套模式绑定到其所在目录，并让更深层的文件覆盖父级
+func TestLoadProjectIgnorePatterns_Nested(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-ignore-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	rese.P1(gogitassist.InitRepo(tempDIR))
+
+	writeIgnoreTestFile(tempDIR, ".git/info/exclude", "secret.txt\n")
+	writeIgnoreTestFile(tempDIR, ".gitignore", "*.log\nbuild/\n")
+	writeIgnoreTestFile(tempDIR, "sub/.gitignore", "!keep.log\n/local.txt\n")
+	writeIgnoreTestFile(tempDIR, "build/.gitignore", "!*\n")
+	for _, name := range []string{"a.log", "local.txt", "secret.txt", "sub/b.log", "sub/keep.log", "sub/local.txt", "sub/deep/local.txt", "build/out.bin"} {
+		writeIgnoreTestFile(tempDIR, name, "content\n")
+	}
+
+	patterns, err := gogitassist.LoadProjectIgnorePatterns(tempDIR)
+	require.NoError(t, err)
+	matcher := gitignore.NewMatcher(patterns)
+	require.True(t, matcher.Match([]string{"a.log"}, false))
+	require.True(t, matcher.Match([]string{"secret.txt"}, false))
+	require.True(t, matcher.Match([]string{"sub", "b.log"}, false))
+	require.False(t, matcher.Match([]string{"sub", "keep.log"}, false))
+	require.False(t, matcher.Match([]string{"local.txt"}, false))
+	require.True(t, matcher.Match([]string{"sub", "local.txt"}, false))
+	require.False(t, matcher.Match([]string{"sub", "deep", "local.txt"}, false))
+
+	// Worktree status hides the same files the git CLI would
+	// 工作树状态隐藏与 git 命令行相同的文件
+	_, tree, err := gogitassist.NewRepoTreeWithIgnore(tempDIR)
+	require.NoError(t, err)
+	status, err := tree.Status()
+	require.NoError(t, err)
+	var paths []string
+	for path := range status {
+		paths = append(paths, path)
+	}
+	require.ElementsMatch(t, []string{".gitignore", "local.txt", "sub/.gitignore", "sub/keep.log", "sub/deep/local.txt"}, paths)
+}
@@ -0,0 +1,317 @@
+package gogitassist
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/go-git/go-git/v5/utils/merkletrie/filesystem"
+	mindex "github.com/go-git/go-git/v5/utils/merkletrie/index"
+	"github.com/go-git/go-git/v5/utils/merkletrie/noder"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/osexistpath/osomitexist"
+)
+
+// StatusIn returns the status of files under the prefix DIR, like git status -- <prefix>
+// Hashes just the files under prefix and reads just the .gitignore files that can apply,
+// so the cost follows the size of the DIR, not the size of the worktree
+// Prefix is slash-separated and relative to the worktree root, blank means the whole worktree
+// Keys of the returned status are relative to the worktree root, same as worktree Status
+//
+// StatusIn 返回 prefix 目录下文件的状态，类似 git status -- <prefix>
+// 仅对 prefix 下的文件计算哈希，并仅读取可能生效的 .gitignore 文件，
+// 因此开销取决于目录大小，而不是工作树大小
+// prefix 以斜杠分隔并相对于工作树根目录，为空表示整个工作树
+// 返回状态的键相对于工作树根目录，与工作树 Status 相同
+func StatusIn(repo *git.Repository, tree *git.Worktree, prefix string) (git.Status, error) {
+	prefix, err := cleanStatusPrefix(prefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if prefix == "" {
+		status, err := tree.Status()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return status, nil
+	}
+
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	scopedIndex := scopeIndex(idx, prefix)
+
+	headNoder, err := headTreeNoder(repo, prefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	left, err := merkletrie.DiffTree(headNoder, mindex.NewRootNode(scopedIndex), statusHashEquals)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	worktreeNoder, err := worktreeNoder(tree, prefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	right, err := merkletrie.DiffTree(mindex.NewRootNode(scopedIndex), worktreeNoder, statusHashEquals)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	matcher, err := scopedIgnoreMatcher(tree, prefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	// Same folding as go-git worktree Status, with paths put back under prefix
+	// 与 go-git 工作树 Status 相同的归并方式，并将路径放回 prefix 下
+	var status = git.Status{}
+	if err := applyStagingChanges(status, prefix, left); err != nil {
+		return nil, erero.Wro(err)
+	}
+	for _, change := range right {
+		action, err := change.Action()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		name := path.Join(prefix, changeName(change))
+		if action == merkletrie.Insert && matcher.Match(strings.Split(name, "/"), change.To.IsDir()) {
+			continue
+		}
+		applyWorktreeChange(status, name, action)
+	}
+	return status, nil
+}
+
+// applyStagingChanges records HEAD to index changes, with paths put under prefix
+//
+// applyStagingChanges 记录 HEAD 到索引的变更，并将路径放在 prefix 下
+func applyStagingChanges(status git.Status, prefix string, changes merkletrie.Changes) error {
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return erero.Wro(err)
+		}
+		fileStatus := status.File(path.Join(prefix, changeName(change)))
+		fileStatus.Worktree = git.Unmodified
+		switch action {
+		case merkletrie.Delete:
+			fileStatus.Staging = git.Deleted
+		case merkletrie.Insert:
+			fileStatus.Staging = git.Added
+		case merkletrie.Modify:
+			fileStatus.Staging = git.Modified
+		}
+	}
+	return nil
+}
+
+// applyWorktreeChange records one index to worktree change, after the staging changes
+//
+// applyWorktreeChange 在暂存变更之后记录一条索引到工作树的变更
+func applyWorktreeChange(status git.Status, name string, action merkletrie.Action) {
+	fileStatus := status.File(name)
+	if fileStatus.Staging == git.Untracked {
+		fileStatus.Staging = git.Unmodified
+	}
+	switch action {
+	case merkletrie.Delete:
+		fileStatus.Worktree = git.Deleted
+	case merkletrie.Insert:
+		fileStatus.Worktree = git.Untracked
+		fileStatus.Staging = git.Untracked
+	case merkletrie.Modify:
+		fileStatus.Worktree = git.Modified
+	}
+}
+
+// cleanStatusPrefix normalizes the prefix, blank for the worktree root
+//
+// cleanStatusPrefix 规范化 prefix，工作树根目录时为空
+func cleanStatusPrefix(prefix string) (string, error) {
+	prefix = strings.Trim(path.Clean("/"+filepath.ToSlash(prefix)), "/")
+	if prefix == ".git" || strings.HasPrefix(prefix, ".git/") {
+		return "", erero.Errorf("wrong status prefix %q", prefix)
+	}
+	return prefix, nil
+}
+
+// scopeIndex copies the index entries under prefix with names made relative to prefix
+//
+// scopeIndex 复制 prefix 下的索引条目，并使名称相对于 prefix
+func scopeIndex(idx *index.Index, prefix string) *index.Index {
+	var scoped = &index.Index{Version: idx.Version}
+	for _, entry := range idx.Entries {
+		name, ok := strings.CutPrefix(entry.Name, prefix+"/")
+		if !ok {
+			continue
+		}
+		scopedEntry := *entry
+		scopedEntry.Name = name
+		scoped.Entries = append(scoped.Entries, &scopedEntry)
+	}
+	return scoped
+}
+
+// headTreeNoder returns the noder of the HEAD subtree at prefix, nil when HEAD or the subtree is missing
+// Blank prefix gives the whole HEAD tree
+//
+// headTreeNoder 返回 HEAD 中 prefix 子树的节点，HEAD 或子树不存在时返回 nil
+// prefix 为空时返回整个 HEAD 树
+func headTreeNoder(repo *git.Repository, prefix string) (noder.Noder, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	headTree, err := commit.Tree()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if prefix == "" {
+		return object.NewTreeRootNode(headTree), nil
+	}
+	subTree, err := headTree.Tree(prefix)
+	if err != nil {
+		if errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	return object.NewTreeRootNode(subTree), nil
+}
+
+// worktreeNoder returns the noder of the prefix DIR on disk, blank when the DIR is missing
+// Submodules under prefix are compared by their checked out commit, as go-git does
+//
+// worktreeNoder 返回磁盘上 prefix 目录的节点，目录不存在时为空
+// 与 go-git 一样，prefix 下的子模块按其检出的提交进行比较
+func worktreeNoder(tree *git.Worktree, prefix string) (noder.Noder, error) {
+	var scopedFS billy.Filesystem = memfs.New()
+	if osomitexist.IsRoot(filepath.Join(tree.Filesystem.Root(), filepath.FromSlash(prefix))) {
+		chrootFS, err := tree.Filesystem.Chroot(prefix)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		scopedFS = chrootFS
+	}
+
+	hashes, err := submoduleHashes(tree, prefix)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return filesystem.NewRootNode(scopedFS, hashes), nil
+}
+
+// submoduleHashes maps submodules under prefix to their checked out commit, names relative to prefix
+// Blank prefix takes every submodule, ones not checked out keep the commit in the index
+//
+// submoduleHashes 将 prefix 下的子模块映射到其检出的提交，名称相对于 prefix
+// prefix 为空时包含所有子模块，未检出的子模块保留索引中的提交
+func submoduleHashes(tree *git.Worktree, prefix string) (map[string]plumbing.Hash, error) {
+	submodules, err := tree.Submodules()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	var hashes = map[string]plumbing.Hash{}
+	for _, submodule := range submodules {
+		name := submodule.Config().Path
+		if prefix != "" {
+			var ok bool
+			if name, ok = strings.CutPrefix(name, prefix+"/"); !ok {
+				continue
+			}
+		}
+		submoduleStatus, err := submodule.Status()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		hashes[name] = submoduleStatus.Current
+		if submoduleStatus.Current.IsZero() {
+			hashes[name] = submoduleStatus.Expected
+		}
+	}
+	return hashes, nil
+}
+
+// scopedIgnoreMatcher builds a matcher from the rules able to apply under prefix
+// Reads info/exclude, the .gitignore files of the prefix parents and those inside prefix,
+// then appends the worktree excludes last, as go-git does
+//
+// scopedIgnoreMatcher 根据可在 prefix 下生效的规则构建匹配器
+// 读取 info/exclude、prefix 各级父目录的 .gitignore 以及 prefix 内的 .gitignore，
+// 然后与 go-git 一样将工作树排除规则追加在最后
+func scopedIgnoreMatcher(tree *git.Worktree, prefix string) (gitignore.Matcher, error) {
+	root := tree.Filesystem.Root()
+	_, commonDIR, err := resolveGitDIRs(root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	rules, err := LoadIgnoreRulesFromPath(filepath.Join(commonDIR, "info", "exclude"), nil)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	components := strings.Split(prefix, "/")
+	for idx := range components {
+		domain := components[:idx]
+		parentRules, err := LoadIgnoreRulesFromPath(filepath.Join(root, filepath.Join(domain...), ".gitignore"), domain)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		rules = append(rules, parentRules...)
+	}
+	if !matchIgnoredPath(rules, components, true).IsIgnored() && osomitexist.IsRoot(filepath.Join(root, filepath.FromSlash(prefix))) {
+		nestedRules, err := loadNestedIgnoreRules(root, components, rules)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		rules = append(rules, nestedRules...)
+	}
+	return gitignore.NewMatcher(append(IgnoreRulePatterns(rules), tree.Excludes...)), nil
+}
+
+// changeName returns the path of a change, taken from the target when present
+//
+// changeName 返回变更的路径，存在目标时取自目标
+func changeName(change merkletrie.Change) string {
+	if name := change.To.String(); name != "" {
+		return name
+	}
+	return change.From.String()
+}
+
+// statusHashEquals compares noder hashes like go-git status does
+// A blank hash marks a DIR without a computed hash, so it never equals
+//
+// statusHashEquals 与 go-git status 一样比较节点哈希
+// 空哈希表示未计算哈希的目录，因此永不相等
+func statusHashEquals(a noder.Hasher, b noder.Hasher) bool {
+	hashA := a.Hash()
+	hashB := b.Hash()
+	if bytes.Equal(hashA, blankNoderHash) || bytes.Equal(hashB, blankNoderHash) {
+		return false
+	}
+	return bytes.Equal(hashA, hashB)
+}
+
+// blankNoderHash is the all-zero hash noders give to DIRs without a computed hash
+//
+// blankNoderHash 是节点为未计算哈希的目录提供的全零哈希
+var blankNoderHash = make([]byte, 24)
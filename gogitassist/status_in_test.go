@@ -0,0 +1,79 @@
+package gogitassist_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestStatusIn verifies scoped status matches the full status filtered to the prefix
+// Covers staged, modified, deleted, untracked and ignored files inside and outside the prefix
+//
+// TestStatusIn 验证限定范围的状态与按 prefix 过滤后的完整状态一致
+// 覆盖 prefix 内外的已暂存、已修改、已删除、未跟踪和被忽略的文件
+func TestStatusIn(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-status-in-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, ".gitignore", "*.log\n")
+	writeIgnoreTestFile(tempDIR, "services/.gitignore", "tmp/\n")
+	writeIgnoreTestFile(tempDIR, "services/api/.gitignore", "*.cache\n")
+	writeIgnoreTestFile(tempDIR, "services/api/main.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "services/api/handler.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "services/api/old.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "services/web/app.js", "app\n")
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+
+	writeIgnoreTestFile(tempDIR, "services/api/main.go", "package main\n\nfunc main() {}\n")
+	writeIgnoreTestFile(tempDIR, "services/api/handler.go", "package main\n\nfunc handle() {}\n")
+	writeIgnoreTestFile(tempDIR, "services/api/new.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "services/api/staged.go", "package main\n")
+	writeIgnoreTestFile(tempDIR, "services/api/debug.log", "log\n")
+	writeIgnoreTestFile(tempDIR, "services/api/data.cache", "cache\n")
+	writeIgnoreTestFile(tempDIR, "services/api/tmp/out.txt", "out\n")
+	writeIgnoreTestFile(tempDIR, "services/web/app.js", "app v2\n")
+	must.Done(os.Remove(filepath.Join(tempDIR, "services/api/old.go")))
+	tree := rese.P1(repo.Worktree())
+	rese.V1(tree.Add("services/api/handler.go"))
+	rese.V1(tree.Add("services/api/staged.go"))
+
+	_, tree, err := gogitassist.NewRepoTreeWithIgnore(tempDIR)
+	require.NoError(t, err)
+	fullStatus := rese.V1(tree.Status())
+
+	for _, prefix := range []string{"services/api", "services/api/", "services", "docs"} {
+		status, err := gogitassist.StatusIn(repo, tree, prefix)
+		require.NoError(t, err)
+		t.Log(prefix, neatjsons.S(status))
+
+		var expected = git.Status{}
+		for name, fileStatus := range fullStatus {
+			if strings.HasPrefix(name, strings.TrimSuffix(prefix, "/")+"/") {
+				expected[name] = fileStatus
+			}
+		}
+		require.Equal(t, expected, status)
+	}
+
+	status, err := gogitassist.StatusIn(repo, tree, "services/api")
+	require.NoError(t, err)
+	require.Equal(t, git.Untracked, status.File("services/api/new.go").Worktree)
+	require.Equal(t, git.Added, status.File("services/api/staged.go").Staging)
+	require.Equal(t, git.Deleted, status.File("services/api/old.go").Worktree)
+	require.NotContains(t, status, "services/api/debug.log")
+	require.NotContains(t, status, "services/api/data.cache")
+	require.NotContains(t, status, "services/api/tmp/out.txt")
+
+	_, err = gogitassist.StatusIn(repo, tree, ".git")
+	require.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package gogitassist
+
+import (
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/yyle88/erero"
+)
+
+// Upstream is the upstream of a local branch, from branch.<name>.remote and branch.<name>.merge
+//
+// Upstream 是本地分支的上游，来自 branch.<name>.remote 和 branch.<name>.merge
+type Upstream struct {
+	Remote      string                 // Remote name, "." with a local upstream branch // 远程名称，上游为本地分支时为 "."
+	Merge       plumbing.ReferenceName // Branch ref on the remote, like refs/heads/main // 远程上的分支引用，如 refs/heads/main
+	TrackingRef plumbing.ReferenceName // Local ref tracking Merge, like refs/remotes/origin/main // 跟踪 Merge 的本地引用，如 refs/remotes/origin/main
+}
+
+// SetUpstream sets the upstream of a local branch, like git branch --set-upstream-to
+// RemoteBranch is a branch name or a full ref, remote "." makes another local branch the upstream
+//
+// SetUpstream 设置本地分支的上游，类似 git branch --set-upstream-to
+// remoteBranch 为分支名或完整引用，remote 为 "." 时将另一个本地分支设为上游
+func SetUpstream(repo *git.Repository, branch string, remote string, remoteBranch string) error {
+	if _, err := repo.Reference(plumbing.NewBranchReferenceName(branch), false); err != nil {
+		return erero.Wrapf(err, "branch %q", branch)
+	}
+	cfg, err := loadRepoConfig(repo)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	if _, ok := cfg.Remotes[remote]; !ok && remote != "." {
+		return erero.Wrapf(git.ErrRemoteNotFound, "remote %q", remote)
+	}
+	merge := plumbing.ReferenceName(remoteBranch)
+	if !strings.HasPrefix(remoteBranch, "refs/") {
+		merge = plumbing.NewBranchReferenceName(remoteBranch)
+	}
+	if err := merge.Validate(); err != nil {
+		return erero.Wrapf(err, "wrong remote branch %q", remoteBranch)
+	}
+
+	branchConfig, ok := cfg.Branches[branch]
+	if !ok {
+		branchConfig = &config.Branch{Name: branch}
+		cfg.Branches[branch] = branchConfig
+	}
+	branchConfig.Remote = remote
+	branchConfig.Merge = merge
+	if err := repo.SetConfig(cfg); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// UnsetUpstream removes the upstream of a local branch, like git branch --unset-upstream
+// Other settings of the branch are kept
+//
+// UnsetUpstream 移除本地分支的上游，类似 git branch --unset-upstream
+// 分支的其他配置保持不变
+func UnsetUpstream(repo *git.Repository, branch string) error {
+	cfg, err := loadRepoConfig(repo)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	branchConfig, ok := cfg.Branches[branch]
+	if !ok {
+		return nil
+	}
+	branchConfig.Remote = ""
+	branchConfig.Merge = ""
+	if err := repo.SetConfig(cfg); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// GetUpstream returns the upstream of a local branch, nil when none is configured
+// The tracking ref follows the fetch refspecs of the remote, as git does
+//
+// GetUpstream 返回本地分支的上游，未配置时返回 nil
+// 与 git 一样，跟踪引用根据远程的拉取 refspec 得出
+func GetUpstream(repo *git.Repository, branch string) (*Upstream, error) {
+	cfg, err := repo.Config()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	branchConfig, ok := cfg.Branches[branch]
+	if !ok || branchConfig.Remote == "" || branchConfig.Merge == "" {
+		return nil, nil
+	}
+	upstream := &Upstream{
+		Remote: branchConfig.Remote,
+		Merge:  branchConfig.Merge,
+	}
+	if upstream.Remote == "." {
+		upstream.TrackingRef = upstream.Merge
+		return upstream, nil
+	}
+	if remote, ok := cfg.Remotes[upstream.Remote]; ok {
+		for _, spec := range remote.Fetch {
+			if spec.Match(upstream.Merge) {
+				upstream.TrackingRef = spec.Dst(upstream.Merge)
+				return upstream, nil
+			}
+		}
+	}
+	// Remote missing or no refspec fetching Merge, fall back to the default layout
+	// 远程不存在或没有 refspec 拉取 Merge 时，回退到默认布局
+	upstream.TrackingRef = plumbing.NewRemoteReferenceName(upstream.Remote, upstream.Merge.Short())
+	return upstream, nil
+}
@@ -0,0 +1,58 @@
+package gogitassist_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestSetUpstream verifies writing and reading branch upstreams, tracking refs following the refspecs
+//
+// TestSetUpstream 验证写入和读取分支上游，跟踪引用遵循 refspec
+func TestSetUpstream(t *testing.T) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogit-upstream-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+	writeIgnoreTestFile(tempDIR, "main.go", "package main\n")
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+	branch := rese.P1(repo.Head()).Name().Short()
+	require.NoError(t, gogitassist.AddRemote(repo, "origin", "https://github.com/example/repo.git"))
+	require.NoError(t, gogitassist.AddRemote(repo, "fork", "https://github.com/account/repo.git"))
+	require.NoError(t, gogitassist.SetRemoteFetchRefSpecs(repo, "fork", "+refs/heads/*:refs/remotes/mirror/*"))
+
+	upstream, err := gogitassist.GetUpstream(repo, branch)
+	require.NoError(t, err)
+	require.Nil(t, upstream)
+
+	require.NoError(t, gogitassist.SetUpstream(repo, branch, "origin", "release/v1"))
+	upstream, err = gogitassist.GetUpstream(repo, branch)
+	require.NoError(t, err)
+	require.Equal(t, &gogitassist.Upstream{
+		Remote:      "origin",
+		Merge:       plumbing.NewBranchReferenceName("release/v1"),
+		TrackingRef: plumbing.NewRemoteReferenceName("origin", "release/v1"),
+	}, upstream)
+
+	require.NoError(t, gogitassist.SetUpstream(repo, branch, "fork", "refs/heads/develop"))
+	upstream = rese.P1(gogitassist.GetUpstream(repo, branch))
+	require.Equal(t, plumbing.ReferenceName("refs/remotes/mirror/develop"), upstream.TrackingRef)
+
+	require.NoError(t, gogitassist.SetUpstream(repo, branch, ".", branch))
+	upstream = rese.P1(gogitassist.GetUpstream(repo, branch))
+	require.Equal(t, plumbing.NewBranchReferenceName(branch), upstream.TrackingRef)
+
+	require.Error(t, gogitassist.SetUpstream(repo, branch, "missing", "main"))
+	require.Error(t, gogitassist.SetUpstream(repo, "missing", "origin", "main"))
+
+	require.NoError(t, gogitassist.UnsetUpstream(repo, branch))
+	upstream, err = gogitassist.GetUpstream(repo, branch)
+	require.NoError(t, err)
+	require.Nil(t, upstream)
+}
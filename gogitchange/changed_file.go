@@ -0,0 +1,78 @@
+package gogitchange
+
+import (
+	"os"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// ChangedFile describes one changed file together with its Git status
+// Carries both relative and absolute paths so callbacks need not rebuild them
+// Mode and Size come from the file on disk at iteration time, blank when deleted
+// RenameFrom and CopyFrom are set when rename detection pairs the file with a source
+//
+// ChangedFile 描述一个变更文件及其 Git 状态
+// 同时携带相对路径和绝对路径，回调无需重新构建
+// Mode 和 Size 来自遍历时磁盘上的文件，已删除时为空
+// 当重命名检测将文件与源配对时设置 RenameFrom 和 CopyFrom
+type ChangedFile struct {
+	Path         string         // Absolute file path // 文件绝对路径
+	RelativePath string         // Slash separated path relative to repo root // 相对于仓库根目录的斜杠分隔路径
+	Staging      git.StatusCode // Status in staging area // 暂存区状态
+	Worktree     git.StatusCode // Status in worktree // 工作树状态
+	RenameFrom   string         // Relative path before rename, blank when not renamed // 重命名前的相对路径，未重命名时为空
+	CopyFrom     string         // Relative path of copy source, blank when not copied // 复制源的相对路径，未复制时为空
+	Mode         os.FileMode    // File mode on disk // 磁盘上的文件模式
+	Size         int64          // File size in bytes on disk // 磁盘上的文件字节大小
+	missing      bool           // Gone from disk, kept just to find deletions // 已不在磁盘上，仅为查找删除而保留
+}
+
+// IsRenamed checks if the file was detected as a rename of another file
+//
+// IsRenamed 检查文件是否被检测为另一个文件的重命名
+func (f ChangedFile) IsRenamed() bool {
+	return f.RenameFrom != ""
+}
+
+// IsCopied checks if the file was detected as a copy of another file
+//
+// IsCopied 检查文件是否被检测为另一个文件的复制
+func (f ChangedFile) IsCopied() bool {
+	return f.CopyFrom != ""
+}
+
+// IsDeleted checks if the file was deleted in the staging area or in the worktree
+//
+// IsDeleted 检查文件是否在暂存区或工作树中被删除
+func (f ChangedFile) IsDeleted() bool {
+	return f.Staging == git.Deleted || f.Worktree == git.Deleted
+}
+
+// newChangedFile builds a ChangedFile from status entry and file info
+// Moves Extra into RenameFrom or CopyFrom based on the status codes
+// Info is nil when the file no longer exists on disk
+//
+// newChangedFile 根据状态条目和文件信息构建 ChangedFile
+// 根据状态码将 Extra 转移到 RenameFrom 或 CopyFrom
+// 当文件在磁盘上已不存在时 info 为 nil
+func newChangedFile(path string, relativePath string, status *git.FileStatus, info os.FileInfo) ChangedFile {
+	file := ChangedFile{
+		Path:         path,
+		RelativePath: relativePath,
+		Staging:      status.Staging,
+		Worktree:     status.Worktree,
+	}
+	if info != nil {
+		file.Mode = info.Mode()
+		file.Size = info.Size()
+	} else {
+		file.missing = true
+	}
+	switch {
+	case status.Staging == git.Renamed || status.Worktree == git.Renamed:
+		file.RenameFrom = status.Extra
+	case status.Staging == git.Copied || status.Worktree == git.Copied:
+		file.CopyFrom = status.Extra
+	}
+	return file
+}
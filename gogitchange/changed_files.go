@@ -8,13 +8,15 @@
 package gogitchange
 
 import (
+	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/yyle88/erero"
 	"github.com/yyle88/must"
 	"github.com/yyle88/osexistpath/osmustexist"
-	"github.com/yyle88/osexistpath/ossoftexist"
 )
 
 // ChangedFileManager manages detection and processing of changed files in Git worktree
@@ -25,41 +27,225 @@ import (
 // 封装项目路径和工作树以实现高效的文件变更操作
 // 为变更文件过滤和处理工作流提供基础
 type ChangedFileManager struct {
-	projectPath string        // Project root path for file operations // 用于文件操作的项目根路径
-	tree        *git.Worktree // Git worktree for status checking // 用于状态检查的 Git 工作树
+	projectPath   string          // Project root path for file operations // 用于文件操作的项目根路径
+	tree          *git.Worktree   // Git worktree for status checking // 用于状态检查的 Git 工作树
+	repo          *git.Repository // Git repo for reading blobs, opened on demand when nil // 用于读取 blob 的 Git 仓库，为 nil 时按需打开
+	renameOptions *RenameOptions  // Rename detection settings, nil means disabled // 重命名检测配置，nil 表示禁用
+	fromRevision  string          // Range start revision in commit range mode // 提交范围模式下的起始修订版本
+	toRevision    string          // Range end revision in commit range mode // 提交范围模式下的结束修订版本
+	sortOrder     SortOrder       // Order of delivered files, path order when blank // 传递文件的顺序，为空时按路径排序
+	subPath       string          // Slash-separated DIR the changes are scoped to, blank for the whole repo // 变更限定的目录（以斜杠分隔），为空表示整个仓库
 }
 
 // NewChangedFileManager creates a new component to handle changed files
 // Validates project path and associates worktree enabling file change detection
+// The project path may be a subDIR of the worktree, then just changes under that DIR are reported
+// Paths are resolved from the worktree root either way
 // Returns configured component usable in changed file processing
 //
 // NewChangedFileManager 创建用于处理变更文件的新组件
 // 验证项目路径并关联工作树以启用文件变更检测
+// 项目路径可以是工作树的子目录，此时仅报告该目录下的变更
+// 无论哪种情况，路径都从工作树根目录解析
 // 返回可用于变更文件处理的配置好的组件
 func NewChangedFileManager(projectPath string, worktree *git.Worktree) *ChangedFileManager {
+	root, subPath := resolveProjectRoot(projectPath)
 	return &ChangedFileManager{
-		projectPath: osmustexist.ROOT(must.Nice(projectPath)),
+		projectPath: root,
 		tree:        worktree,
+		subPath:     subPath,
 	}
 }
 
+// OpenChangedFileManager creates a component from any path inside a worktree
+// Discovers the enclosing repo, subDIRs and linked worktrees included, and applies ignore patterns
+// Changes are scoped to the DIR of path, like NewChangedFileManager
+//
+// OpenChangedFileManager 从工作树内的任意路径创建组件
+// 查找所在的仓库（包括子目录和链接工作树）并应用忽略模式
+// 与 NewChangedFileManager 一样，变更限定在路径所在目录
+func OpenChangedFileManager(path string) (*ChangedFileManager, error) {
+	location, err := gogitassist.DiscoverRepo(path)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	repo, tree, err := gogitassist.NewRepoTreeWithIgnore(location.Root)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	manager := NewChangedFileManager(location.Root, tree).WithRepo(repo)
+	manager.subPath = location.SubPath
+	return manager, nil
+}
+
+// resolveProjectRoot validates the project path and returns the root of its enclosing worktree with the subPath
+// Keeps the path as given when no repo encloses it
+//
+// resolveProjectRoot 验证项目路径并返回其所在工作树的根目录以及子路径
+// 没有仓库包含该路径时保持原样
+func resolveProjectRoot(projectPath string) (string, string) {
+	projectPath = osmustexist.ROOT(must.Nice(projectPath))
+	location, err := gogitassist.DiscoverRepo(projectPath)
+	if err != nil {
+		return projectPath, ""
+	}
+	return location.Root, location.SubPath
+}
+
+// WithRepo sets the repo used to read file contents and returns the manager
+// When not set, the repo is opened from project path on demand
+//
+// WithRepo 设置用于读取文件内容的仓库并返回管理器
+// 未设置时，按需从项目路径打开仓库
+func (m *ChangedFileManager) WithRepo(repo *git.Repository) *ChangedFileManager {
+	m.repo = repo
+	return m
+}
+
+// WithRenameDetection enables similarity-based rename detection and returns the manager
+// Renamed files are delivered with Renamed status and RenameFrom set, sources are no longer reported
+// Pass nil to disable detection
+//
+// WithRenameDetection 启用基于相似度的重命名检测并返回管理器
+// 被重命名的文件以 Renamed 状态传递并设置 RenameFrom，源文件不再被报告
+// 传入 nil 以禁用检测
+func (m *ChangedFileManager) WithRenameDetection(renameOptions *RenameOptions) *ChangedFileManager {
+	m.renameOptions = renameOptions
+	return m
+}
+
+// WithSortOrder sets the order in which changed files are delivered and returns the manager
+// Applies to Foreach, ForeachChange, ForeachChangedGoFile and the List methods
+//
+// WithSortOrder 设置变更文件的传递顺序并返回管理器
+// 适用于 Foreach、ForeachChange、ForeachChangedGoFile 以及 List 方法
+func (m *ChangedFileManager) WithSortOrder(sortOrder SortOrder) *ChangedFileManager {
+	m.sortOrder = sortOrder
+	return m
+}
+
+// getRepo returns the configured repo, opening it from project path when unset
+//
+// getRepo 返回配置的仓库，未设置时从项目路径打开
+func (m *ChangedFileManager) getRepo() (*git.Repository, error) {
+	if m.repo == nil {
+		repo, err := gogitassist.NewRepo(m.projectPath)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		m.repo = repo
+	}
+	return m.repo, nil
+}
+
+// loadStatus reads worktree status and applies rename detection when enabled
+// Diffs the commit range instead when the manager was created from commits
+// Just the subPath DIR is read when the manager is scoped to one
+//
+// loadStatus 读取工作树状态，启用时应用重命名检测
+// 当管理器由提交创建时，改为比较提交范围的差异
+// 管理器限定到子目录时仅读取该子路径目录
+func (m *ChangedFileManager) loadStatus() (git.Status, error) {
+	if m.toRevision != "" {
+		statusMap, err := m.loadCommitRangeStatus()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		for relativePath := range statusMap {
+			if m.subPath != "" && !strings.HasPrefix(relativePath, m.subPath+"/") {
+				delete(statusMap, relativePath)
+			}
+		}
+		return statusMap, nil
+	}
+	repo, err := m.getRepo()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	statusMap, err := gogitassist.StatusIn(repo, m.tree, m.subPath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if m.renameOptions != nil {
+		if err := DetectRenames(repo, m.tree, statusMap, m.renameOptions); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+	return statusMap, nil
+}
+
 // Foreach iterates through changed files (excluding deleted) and processes each
 // Applies matching options to screen files by type and path criteria
 // Executes provided process function on each qualifying changed file
+// Deleted files go to the MatchOptions OnDeleted callback when one is set
 //
 // Foreach 遍历变更的文件（排除已删除的）并处理每个文件
 // 应用匹配选项按类型和路径条件过滤文件
 // 对每个符合条件的变更文件执行提供的处理函数
 func (m *ChangedFileManager) Foreach(matchOptions *MatchOptions, process func(path string) error) error {
-	statusMap, err := m.tree.Status()
+	if err := m.ForeachChange(matchOptions, func(file ChangedFile) error {
+		return process(file.Path)
+	}); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// ForeachChange iterates through changed files (excluding deleted) and passes full descriptors
+// Applies the same screening as Foreach, while keeping status codes, rename source, mode and size
+// Lets callers decide per file what to do without re-querying status
+// Deleted files go to the MatchOptions OnDeleted callback when one is set, in the same order
+//
+// ForeachChange 遍历变更的文件（排除已删除的）并传递完整描述
+// 应用与 Foreach 相同的过滤，同时保留状态码、重命名源、文件模式和大小
+// 让调用方无需重新查询状态即可逐个文件决定处理方式
+// 设置了 MatchOptions 的 OnDeleted 回调时，已删除的文件按相同顺序传给该回调
+func (m *ChangedFileManager) ForeachChange(matchOptions *MatchOptions, process func(file ChangedFile) error) error {
+	files, err := m.collectChangedFiles(matchOptions, matchOptions.onDeleted != nil)
 	if err != nil {
 		return erero.Wro(err)
 	}
+	var loadContent = m.lastKnownContent()
+	for _, file := range files {
+		// Route deleted files to the separate callback when one is set
+		// 设置了独立回调时将已删除的文件交给该回调
+		if matchOptions.onDeleted != nil && file.IsDeleted() {
+			if err := matchOptions.onDeleted(DeletedFile{ChangedFile: file, load: loadContent}); err != nil {
+				return erero.Wro(err)
+			}
+			continue
+		}
+		// Skip missing files kept just to find deletions
+		// 跳过仅为查找删除而保留的缺失文件
+		if file.missing {
+			continue
+		}
+
+		// Execute custom processing function on the file
+		// 对文件执行自定义处理函数
+		if err := process(file); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
 
+// collectChangedFiles screens changed files and arranges them in the configured order
+// Deleted and missing files are kept with blank mode and size when keepDeleted is set
+//
+// collectChangedFiles 过滤变更文件并按配置的顺序排列
+// 设置 keepDeleted 时保留已删除和缺失的文件，其模式和大小为空
+func (m *ChangedFileManager) collectChangedFiles(matchOptions *MatchOptions, keepDeleted bool) ([]ChangedFile, error) {
+	statusMap, err := m.loadStatus()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var files = make([]ChangedFile, 0, len(statusMap))
 	for relativePath, status := range statusMap {
 		// Screen out deleted files as these cannot be processed
 		// 过滤掉已删除的文件，因为它们无法被处理
-		if status.Staging == git.Deleted {
+		if status.Staging == git.Deleted && !keepDeleted {
 			continue
 		}
 
@@ -69,33 +255,34 @@ func (m *ChangedFileManager) Foreach(matchOptions *MatchOptions, process func(pa
 			continue
 		}
 
-		// Screen files by extension if type matching is specified
-		// 如果指定了类型匹配，则按扩展名过滤文件
-		if matchOptions.matchType != "" && filepath.Ext(relativePath) != matchOptions.matchType {
-			continue
-		}
-
-		// Screen files by path criteria using custom match function
-		// 使用自定义匹配器函数按路径条件过滤文件
-		if matchOptions.matchPath != nil && !matchOptions.matchPath(filepath.Join(m.projectPath, relativePath)) {
-			continue
-		}
-
 		// Construct complete file path for processing
 		// 构建用于处理的完整文件路径
 		path := filepath.Join(m.projectPath, relativePath)
 
-		// Process file when it exists (not deleted or missing)
-		// 仅在文件存在时处理（未删除或缺失）
-		if ossoftexist.IsFile(path) {
-			// Execute custom processing function on the file
-			// 对文件执行自定义处理函数
-			if err := process(path); err != nil {
-				return erero.Wro(err)
+		// Screen files by extension, rules and custom path criteria
+		// 按扩展名、规则和自定义路径条件过滤文件
+		if !matchOptions.HasPathMatch(relativePath, path) {
+			continue
+		}
+
+		// Keep file when it exists (not deleted or missing), symlinks are not followed
+		// so a tracked link never gets processed, or rewritten, as the file it points to
+		// 仅在文件存在时保留（未删除或缺失），不跟随符号链接，
+		// 使被跟踪的链接不会被当作其指向的文件处理或重写
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			if keepDeleted && os.IsNotExist(err) {
+				files = append(files, newChangedFile(path, relativePath, status, nil))
 			}
+			continue
 		}
+		files = append(files, newChangedFile(path, relativePath, status, info))
 	}
-	return nil
+
+	// Arrange files so runs are reproducible regardless of map iteration
+	// 排列文件，使运行结果不受映射遍历顺序影响
+	SortChangedFiles(files, m.sortOrder)
+	return files, nil
 }
 
 // ListChangedFilePaths returns list of changed file paths matching specified criteria
@@ -116,6 +303,19 @@ func (m *ChangedFileManager) ListChangedFilePaths(matchOptions *MatchOptions) ([
 	return paths, nil
 }
 
+// ListChangedFiles returns descriptors of changed files matching specified criteria
+// Each descriptor carries paths, status codes and rename source when detected
+//
+// ListChangedFiles 返回符合指定条件的变更文件描述列表
+// 每个描述包含路径、状态码以及检测到的重命名源
+func (m *ChangedFileManager) ListChangedFiles(matchOptions *MatchOptions) ([]ChangedFile, error) {
+	files, err := m.collectChangedFiles(matchOptions, false)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return files, nil
+}
+
 // FormatChangedGoFiles formats all changed Go code files (features moved to distinct module)
 // This function has been relocated to go-mate/go-commit for improved design
 //
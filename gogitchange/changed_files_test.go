@@ -1,18 +1,54 @@
 package gogitchange_test
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/go-git/go-git/v5"
 	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/go-xlan/gogit/gogitchange"
 	"github.com/stretchr/testify/require"
 	"github.com/yyle88/erero"
 	"github.com/yyle88/formatgo"
+	"github.com/yyle88/must"
 	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
 	"github.com/yyle88/runpath"
 )
 
+// setupTestRepo creates a temp git repo with the given files committed
+// Environment setup must succeed, so we use rese/must to handle all tasks
+// Returns the temp DIR path together with repo and worktree
+//
+// setupTestRepo 创建提交了给定文件的临时 git 仓库
+// 环境设置必须成功，因此我们使用 rese/must 处理所有任务
+// 返回临时 DIR 路径以及仓库和工作树
+func setupTestRepo(t *testing.T, files map[string]string) (string, *git.Repository, *git.Worktree) {
+	tempDIR := rese.V1(os.MkdirTemp("", "gogitchange-test-*"))
+	t.Cleanup(func() {
+		must.Done(os.RemoveAll(tempDIR))
+	})
+
+	repo := rese.P1(gogitassist.InitRepo(tempDIR))
+	for name, content := range files {
+		writeTestFile(tempDIR, name, content)
+	}
+	rese.V1(gogitassist.Commit(repo, "Base commit", "Test Account", "test@example.com"))
+
+	return tempDIR, repo, rese.P1(repo.Worktree())
+}
+
+// writeTestFile writes content to a file under root, creating parent DIRs
+//
+// writeTestFile 将内容写入 root 下的文件，并创建父目录
+func writeTestFile(root string, name string, content string) {
+	path := filepath.Join(root, name)
+	must.Done(os.MkdirAll(filepath.Dir(path), 0755))
+	must.Done(os.WriteFile(path, []byte(content), 0644))
+}
+
 // TestListChangedFilePaths_Markdown tests listing changed Markdown files
 // Verifies file path collection for .md files using type matching
 //
@@ -106,3 +142,58 @@ func TestFormatChangedGoFiles(t *testing.T) {
 		return nil
 	}))
 }
+
+// TestChangedFileManager_ForeachChange verifies descriptors passed to callbacks
+// Should carry paths, status codes, mode and size of each changed file
+//
+// TestChangedFileManager_ForeachChange 验证传递给回调的描述
+// 应该携带每个变更文件的路径、状态码、模式和大小
+func TestChangedFileManager_ForeachChange(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"main.go": "package main\n"})
+
+	writeTestFile(root, "main.go", "package main\n\nfunc main() {}\n")
+	writeTestFile(root, "docs/guide.md", "# Guide\n")
+
+	var files = map[string]gogitchange.ChangedFile{}
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	require.NoError(t, manager.ForeachChange(gogitchange.NewMatchOptions(), func(file gogitchange.ChangedFile) error {
+		files[file.RelativePath] = file
+		return nil
+	}))
+	t.Log(neatjsons.S(files))
+
+	require.Len(t, files, 2)
+	require.Equal(t, filepath.Join(root, "main.go"), files["main.go"].Path)
+	require.Equal(t, git.Modified, files["main.go"].Worktree)
+	require.Equal(t, int64(len("package main\n\nfunc main() {}\n")), files["main.go"].Size)
+	require.True(t, files["main.go"].Mode.IsRegular())
+	require.Equal(t, git.Untracked, files["docs/guide.md"].Worktree)
+	require.False(t, files["docs/guide.md"].IsRenamed())
+}
+
+// TestOpenChangedFileManager verifies creating a manager scoped to a subDIR of the worktree
+// Should report just changes under the subDIR, with paths resolved from the worktree root
+//
+// TestOpenChangedFileManager 验证创建限定到工作树子目录的管理器
+// 应该仅报告子目录下的变更，路径从工作树根目录解析
+func TestOpenChangedFileManager(t *testing.T) {
+	root, repo, tree := setupTestRepo(t, map[string]string{"main.go": "package main\n", "docs/guide.md": "# Guide\n"})
+
+	writeTestFile(root, "main.go", "package main\n\nfunc main() {}\n")
+	writeTestFile(root, "docs/guide.md", "# Guide\n\nMore\n")
+	writeTestFile(root, "docs/api/index.md", "# API\n")
+
+	manager, err := gogitchange.OpenChangedFileManager(filepath.Join(root, "docs"))
+	require.NoError(t, err)
+	paths, err := manager.ListChangedFilePaths(gogitchange.NewMatchOptions())
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(root, "docs/api/index.md"), filepath.Join(root, "docs/guide.md")}, paths)
+
+	paths, err = gogitchange.NewChangedFileManager(filepath.Join(root, "docs", "api"), tree).ListChangedFilePaths(gogitchange.NewMatchOptions())
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(root, "docs/api/index.md")}, paths)
+
+	paths, err = gogitchange.NewChangedFileManager(root, tree).WithRepo(repo).ListChangedFilePaths(gogitchange.NewMatchOptions())
+	require.NoError(t, err)
+	require.Len(t, paths, 3)
+}
@@ -0,0 +1,137 @@
+package gogitchange
+
+import (
+	"context"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/must"
+)
+
+// NewChangedFileManagerFromCommits creates a component that computes changes between two commits
+// Revisions accept anything git can resolve, such as "main", "HEAD~3", tags and hashes
+// Changes are reported in staging codes (Added, Modified, Deleted, Renamed) with clean worktree codes
+// Files are delivered from the worktree, so it should be checked out at the target revision
+// A project path inside a subDIR scopes the changes to that DIR
+//
+// NewChangedFileManagerFromCommits 创建计算两个提交之间变更的组件
+// 修订版本接受 git 可以解析的任何内容，如 "main"、"HEAD~3"、标签和哈希
+// 变更以暂存区状态码（Added、Modified、Deleted、Renamed）报告，工作树状态码为未修改
+// 文件从工作树传递，因此工作树应检出在目标修订版本
+// 项目路径位于子目录时，变更限定在该目录内
+func NewChangedFileManagerFromCommits(projectPath string, repo *git.Repository, fromRevision string, toRevision string) *ChangedFileManager {
+	root, subPath := resolveProjectRoot(projectPath)
+	return &ChangedFileManager{
+		projectPath:  root,
+		repo:         must.Nice(repo),
+		fromRevision: must.Nice(fromRevision),
+		toRevision:   must.Nice(toRevision),
+		subPath:      subPath,
+	}
+}
+
+// MergeBase finds the best common ancestor of two revisions
+// Use with NewChangedFileManagerFromCommits to get "files changed in this branch"
+// Returns error when the revisions share no history
+//
+// MergeBase 查找两个修订版本的最佳共同祖先
+// 与 NewChangedFileManagerFromCommits 一起使用以获取"此分支中变更的文件"
+// 当修订版本没有共同历史时返回错误
+func MergeBase(repo *git.Repository, revisionA string, revisionB string) (plumbing.Hash, error) {
+	commitA, err := resolveCommit(repo, revisionA)
+	if err != nil {
+		return plumbing.ZeroHash, erero.Wro(err)
+	}
+	commitB, err := resolveCommit(repo, revisionB)
+	if err != nil {
+		return plumbing.ZeroHash, erero.Wro(err)
+	}
+	bases, err := commitA.MergeBase(commitB)
+	if err != nil {
+		return plumbing.ZeroHash, erero.Wro(err)
+	}
+	if len(bases) == 0 {
+		return plumbing.ZeroHash, erero.Errorf("no merge base between %s and %s", revisionA, revisionB)
+	}
+	return bases[0].Hash, nil
+}
+
+// loadCommitRangeStatus diffs the trees of the two revisions into a status map
+// Applies tree rename detection when rename options are configured
+//
+// loadCommitRangeStatus 将两个修订版本的树差异转换为状态映射
+// 配置了重命名选项时应用树的重命名检测
+func (m *ChangedFileManager) loadCommitRangeStatus() (git.Status, error) {
+	fromTree, err := resolveTree(m.repo, m.fromRevision)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	toTree, err := resolveTree(m.repo, m.toRevision)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var diffOptions = &object.DiffTreeOptions{}
+	if m.renameOptions != nil {
+		diffOptions.DetectRenames = true
+		diffOptions.RenameScore = uint(m.renameOptions.threshold)
+	}
+	changes, err := object.DiffTreeWithOptions(context.Background(), fromTree, toTree, diffOptions)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var statusMap = make(git.Status, len(changes))
+	for _, change := range changes {
+		action, err := change.Action()
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		switch action {
+		case merkletrie.Insert:
+			statusMap[change.To.Name] = &git.FileStatus{Staging: git.Added, Worktree: git.Unmodified}
+		case merkletrie.Delete:
+			statusMap[change.From.Name] = &git.FileStatus{Staging: git.Deleted, Worktree: git.Unmodified}
+		case merkletrie.Modify:
+			if change.From.Name != change.To.Name {
+				statusMap[change.To.Name] = &git.FileStatus{Staging: git.Renamed, Worktree: git.Unmodified, Extra: change.From.Name}
+			} else {
+				statusMap[change.To.Name] = &git.FileStatus{Staging: git.Modified, Worktree: git.Unmodified}
+			}
+		}
+	}
+	return statusMap, nil
+}
+
+// resolveCommit resolves a revision into its commit object
+//
+// resolveCommit 将修订版本解析为提交对象
+func resolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return commit, nil
+}
+
+// resolveTree resolves a revision into the tree of its commit
+//
+// resolveTree 将修订版本解析为其提交的树
+func resolveTree(repo *git.Repository, revision string) (*object.Tree, error) {
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return tree, nil
+}
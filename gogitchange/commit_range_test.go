@@ -0,0 +1,80 @@
+package gogitchange_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestNewChangedFileManagerFromCommits verifies change sets computed from a commit range
+// Should report added, modified and renamed files while screening out deletions
+//
+// TestNewChangedFileManagerFromCommits 验证从提交范围计算的变更集
+// 应该报告新增、修改和重命名的文件，同时过滤掉删除的文件
+func TestNewChangedFileManagerFromCommits(t *testing.T) {
+	root, repo, _ := setupTestRepo(t, map[string]string{
+		"keep.go":   "package demo\n",
+		"edit.go":   "package demo\n",
+		"remove.go": "package demo\n",
+		"move.go":   renameTestContent,
+	})
+	baseHash := rese.V1(repo.Head()).Hash()
+
+	writeTestFile(root, "edit.go", "package demo\n\nvar Edited = true\n")
+	writeTestFile(root, "add.md", "# Added\n")
+	must.Done(os.Remove(filepath.Join(root, "remove.go")))
+	must.Done(os.Rename(filepath.Join(root, "move.go"), filepath.Join(root, "moved.go")))
+	rese.V1(gogitassist.Commit(repo, "Second commit", "Test Account", "test@example.com"))
+
+	manager := gogitchange.NewChangedFileManagerFromCommits(root, repo, baseHash.String(), "HEAD").
+		WithRenameDetection(gogitchange.NewRenameOptions())
+	files, err := manager.ListChangedFiles(gogitchange.NewMatchOptions())
+	require.NoError(t, err)
+	t.Log(neatjsons.S(files))
+
+	var statuses = map[string]git.StatusCode{}
+	for _, file := range files {
+		statuses[file.RelativePath] = file.Staging
+	}
+	require.Equal(t, map[string]git.StatusCode{
+		"edit.go":  git.Modified,
+		"add.md":   git.Added,
+		"moved.go": git.Renamed,
+	}, statuses)
+
+	paths, err := manager.ListChangedFilePaths(gogitchange.NewMatchOptions().MatchType(".go").MatchStatus(git.Modified))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(root, "edit.go")}, paths)
+}
+
+// TestMergeBase verifies finding the common ancestor of two branches
+// Should return the fork point commit of a feature branch
+//
+// TestMergeBase 验证查找两个分支的共同祖先
+// 应该返回特性分支的分叉点提交
+func TestMergeBase(t *testing.T) {
+	root, repo, _ := setupTestRepo(t, map[string]string{"main.go": "package main\n"})
+	forkHash := rese.V1(repo.Head()).Hash()
+	must.Done(repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/feature", forkHash)))
+
+	writeTestFile(root, "next.go", "package main\n")
+	rese.V1(gogitassist.Commit(repo, "Next commit", "Test Account", "test@example.com"))
+
+	baseHash, err := gogitchange.MergeBase(repo, "feature", "HEAD")
+	require.NoError(t, err)
+	require.Equal(t, forkHash, baseHash)
+
+	manager := gogitchange.NewChangedFileManagerFromCommits(root, repo, baseHash.String(), "HEAD")
+	paths, err := manager.ListChangedFilePaths(gogitchange.NewMatchOptions())
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(root, "next.go")}, paths)
+}
@@ -0,0 +1,83 @@
+package gogitchange
+
+import (
+	"github.com/yyle88/erero"
+)
+
+// DeletedFile describes a deleted file delivered to the OnDeleted callback
+// The last-known content is read on demand, so callbacks not needing it pay nothing
+//
+// DeletedFile 描述传递给 OnDeleted 回调的已删除文件
+// 最后已知的内容按需读取，不需要内容的回调无额外开销
+type DeletedFile struct {
+	ChangedFile                                           // Descriptor with blank mode and size // 模式和大小为空的文件描述
+	load        func(relativePath string) ([]byte, error) // Reads last-known content // 读取最后已知的内容
+}
+
+// Content returns the last-known content of the deleted file
+// Reads HEAD in worktree mode, falling back to the index for files never committed
+// Reads the range start revision when the manager was created from commits
+//
+// Content 返回已删除文件最后已知的内容
+// 工作树模式下读取 HEAD，对从未提交的文件回退到索引
+// 当管理器由提交创建时读取范围的起始修订版本
+func (f DeletedFile) Content() ([]byte, error) {
+	content, err := f.load(f.RelativePath)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return content, nil
+}
+
+// lastKnownContent returns a function reading contents of deleted files
+// The content loader is prepared on first use and reused for later files
+//
+// lastKnownContent 返回读取已删除文件内容的函数
+// 内容加载器在首次使用时准备，并被后续文件复用
+func (m *ChangedFileManager) lastKnownContent() func(relativePath string) ([]byte, error) {
+	var loader *contentLoader
+	return func(relativePath string) ([]byte, error) {
+		if loader == nil {
+			prepared, err := m.newDeletedContentLoader()
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			loader = prepared
+		}
+		content, exists, err := loader.headContent(relativePath)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if !exists && loader.idx != nil {
+			if content, exists, err = loader.indexContent(relativePath); err != nil {
+				return nil, erero.Wro(err)
+			}
+		}
+		if !exists {
+			return nil, erero.Errorf("no recorded content of %s", relativePath)
+		}
+		return content, nil
+	}
+}
+
+// newDeletedContentLoader prepares a loader whose base tree is HEAD or the range start
+//
+// newDeletedContentLoader 准备以 HEAD 或范围起点为基础树的加载器
+func (m *ChangedFileManager) newDeletedContentLoader() (*contentLoader, error) {
+	repo, err := m.getRepo()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	if m.toRevision != "" {
+		fromTree, err := resolveTree(repo, m.fromRevision)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return &contentLoader{repo: repo, headTree: fromTree}, nil
+	}
+	loader, err := newContentLoader(repo, m.tree)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return loader, nil
+}
@@ -0,0 +1,164 @@
+package gogitchange_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitassist"
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// TestChangedFileManager_ForeachChange_OnDeleted verifies deletions reach the separate callback
+// Should deliver both worktree and staged deletions with HEAD content, others go to process
+//
+// TestChangedFileManager_ForeachChange_OnDeleted 验证删除的文件传给独立回调
+// 应该传递工作树和暂存区的删除并提供 HEAD 内容，其他文件交给处理函数
+func TestChangedFileManager_ForeachChange_OnDeleted(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{
+		"gone.txt":   "gone content\n",
+		"staged.txt": "staged content\n",
+		"keep.txt":   "keep\n",
+	})
+	must.Done(os.Remove(filepath.Join(root, "gone.txt")))
+	rese.V1(tree.Remove("staged.txt"))
+	writeTestFile(root, "keep.txt", "keep changed\n")
+
+	var processed []string
+	var deleted = map[string]string{}
+	options := gogitchange.NewMatchOptions().OnDeleted(func(file gogitchange.DeletedFile) error {
+		require.Zero(t, file.Mode)
+		content, err := file.Content()
+		require.NoError(t, err)
+		deleted[file.RelativePath] = string(content)
+		return nil
+	})
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	require.NoError(t, manager.ForeachChange(options, func(file gogitchange.ChangedFile) error {
+		processed = append(processed, file.RelativePath)
+		return nil
+	}))
+
+	require.Equal(t, []string{"keep.txt"}, processed)
+	require.Equal(t, map[string]string{
+		"gone.txt":   "gone content\n",
+		"staged.txt": "staged content\n",
+	}, deleted)
+
+	// Without the callback, deletions stay skipped
+	// 未设置回调时，删除的文件仍被跳过
+	paths := rese.V1(manager.ListChangedFilePaths(gogitchange.NewMatchOptions()))
+	require.Equal(t, []string{filepath.Join(root, "keep.txt")}, paths)
+}
+
+// TestChangedFileManager_ForeachChange_OnDeleted_CommitRange verifies content comes from the range start
+//
+// TestChangedFileManager_ForeachChange_OnDeleted_CommitRange 验证内容来自范围起点
+func TestChangedFileManager_ForeachChange_OnDeleted_CommitRange(t *testing.T) {
+	root, repo, _ := setupTestRepo(t, map[string]string{"old.txt": "old content\n", "keep.txt": "keep\n"})
+	must.Done(os.Remove(filepath.Join(root, "old.txt")))
+	rese.V1(gogitassist.Commit(repo, "Drop old", "Test Account", "test@example.com"))
+
+	var deleted []string
+	options := gogitchange.NewMatchOptions().OnDeleted(func(file gogitchange.DeletedFile) error {
+		content, err := file.Content()
+		require.NoError(t, err)
+		deleted = append(deleted, file.RelativePath+"="+string(content))
+		return nil
+	})
+	manager := gogitchange.NewChangedFileManagerFromCommits(root, repo, "HEAD~1", "HEAD")
+	require.NoError(t, manager.ForeachChange(options, func(file gogitchange.ChangedFile) error {
+		return nil
+	}))
+	require.Equal(t, []string{"old.txt=old content\n"}, deleted)
+}
+
+// TestChangedFileManager_ForeachChangeParallel_OnDeleted verifies parallel mode honors the separate callback
+// Deletions reach the callback with HEAD content and their failures come back as FileErrors
+//
+// TestChangedFileManager_ForeachChangeParallel_OnDeleted 验证并行模式遵循独立回调
+// 删除的文件带着 HEAD 内容传给回调，其失败以 FileErrors 的形式返回
+func TestChangedFileManager_ForeachChangeParallel_OnDeleted(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{
+		"gone.txt": "gone content\n",
+		"keep.txt": "keep\n",
+	})
+	must.Done(os.Remove(filepath.Join(root, "gone.txt")))
+	writeTestFile(root, "keep.txt", "keep changed\n")
+	writeTestFile(root, "new.txt", "new\n")
+
+	var deleted = map[string]string{}
+	options := gogitchange.NewMatchOptions().OnDeleted(func(file gogitchange.DeletedFile) error {
+		content, err := file.Content()
+		require.NoError(t, err)
+		deleted[file.RelativePath] = string(content)
+		return nil
+	})
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	var processed sync.Map
+	require.NoError(t, manager.ForeachChangeParallel(options, gogitchange.NewParallelOptions().Workers(2), func(file gogitchange.ChangedFile) error {
+		processed.Store(file.RelativePath, true)
+		return nil
+	}))
+	require.Equal(t, map[string]string{"gone.txt": "gone content\n"}, deleted)
+	var paths []string
+	processed.Range(func(key, value any) bool {
+		paths = append(paths, key.(string))
+		return true
+	})
+	require.ElementsMatch(t, []string{"keep.txt", "new.txt"}, paths)
+
+	options = gogitchange.NewMatchOptions().OnDeleted(func(file gogitchange.DeletedFile) error {
+		return errors.New("broken")
+	})
+	err := manager.ForeachChangeParallel(options, gogitchange.NewParallelOptions().CollectErrors(true), func(file gogitchange.ChangedFile) error {
+		return nil
+	})
+	var fileErrors gogitchange.FileErrors
+	require.True(t, errors.As(err, &fileErrors))
+	require.Len(t, fileErrors, 1)
+	require.Equal(t, "gone.txt", fileErrors[0].File.RelativePath)
+}
+
+// TestChangedFileManager_ForeachChange_OnDeleted_NoPermission verifies a file with no permission bits is still processed
+// Its mode is zero, yet it exists on disk, so it must not be taken as a missing file
+//
+// TestChangedFileManager_ForeachChange_OnDeleted_NoPermission 验证没有权限位的文件仍会被处理
+// 其模式为零但文件存在于磁盘上，因此不能被当作缺失文件
+func TestChangedFileManager_ForeachChange_OnDeleted_NoPermission(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("windows has no unix permission bits")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("go-git reads file content to build status, which needs root once permission bits are gone")
+	}
+	root, _, tree := setupTestRepo(t, map[string]string{"keep.txt": "keep\n"})
+	writeTestFile(root, "locked.txt", "locked\n")
+	must.Done(os.Chmod(filepath.Join(root, "locked.txt"), 0))
+
+	options := gogitchange.NewMatchOptions().OnDeleted(func(file gogitchange.DeletedFile) error {
+		return errors.New("unexpected deletion " + file.RelativePath)
+	})
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	var processed []string
+	require.NoError(t, manager.ForeachChange(options, func(file gogitchange.ChangedFile) error {
+		require.Zero(t, file.Mode)
+		processed = append(processed, file.RelativePath)
+		return nil
+	}))
+	require.Equal(t, []string{"locked.txt"}, processed)
+
+	var parallelProcessed sync.Map
+	require.NoError(t, manager.ForeachChangeParallel(options, gogitchange.NewParallelOptions(), func(file gogitchange.ChangedFile) error {
+		parallelProcessed.Store(file.RelativePath, true)
+		return nil
+	}))
+	_, ok := parallelProcessed.Load("locked.txt")
+	require.True(t, ok)
+}
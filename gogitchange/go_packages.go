@@ -0,0 +1,322 @@
+package gogitchange
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yyle88/erero"
+	"golang.org/x/mod/modfile"
+)
+
+// PackageOptions configures how changed files expand into changed Go packages
+// Supports fluent configuration pattern for convenient setup
+//
+// PackageOptions 配置变更文件如何扩展为变更的 Go 包
+// 支持流畅配置模式以便于设置
+type PackageOptions struct {
+	reverseDeps bool // Expand to importers within the same module // 扩展到同一模块内的导入方
+}
+
+// NewPackageOptions creates package options reporting just directly changed packages
+//
+// NewPackageOptions 创建仅报告直接变更包的包选项
+func NewPackageOptions() *PackageOptions {
+	return &PackageOptions{}
+}
+
+// ReverseDeps enables expansion to packages importing changed ones and returns updated PackageOptions
+// Expansion is transitive and stays within the enclosing module, test files count as importers
+//
+// ReverseDeps 启用向导入变更包的包扩展并返回更新的 PackageOptions
+// 扩展是传递性的，且限于所在模块内，测试文件也算作导入方
+func (o *PackageOptions) ReverseDeps(enable bool) *PackageOptions {
+	o.reverseDeps = enable
+	return o
+}
+
+// ChangedPackage describes a Go package affected by the change set
+//
+// ChangedPackage 描述受变更集影响的 Go 包
+type ChangedPackage struct {
+	ImportPath        string        // Package import path // 包导入路径
+	ModulePath        string        // Path of the enclosing module // 所在模块的路径
+	Dir               string        // Absolute package DIR, might be gone when deleted // 包的绝对目录，删除后可能不存在
+	Files             []ChangedFile // Changed Go files of this package, deleted ones included, blank when it just lost a renamed file // 此包中变更的 Go 文件，包括已删除的，仅因文件被重命名移出时为空
+	ModuleChanged     bool          // The go.mod or go.sum of the module changed // 模块的 go.mod 或 go.sum 发生变更
+	ReverseDependency bool          // Included just because it imports an affected package // 仅因导入受影响的包而被包含
+}
+
+// ListChangedGoPackages maps changed Go files to the import paths of their packages
+// Reads the enclosing go.mod of each file, deleted files included, a deleted go.mod is read from HEAD
+// A changed go.mod or go.sum marks every package of that module as changed
+// A renamed Go file also marks the package it was moved out of
+// Pass match options without extension screens so go.mod and go.sum are seen
+// Build constraints are not evaluated, every Go file counts
+// Returns packages sorted by import path
+//
+// ListChangedGoPackages 将变更的 Go 文件映射到其所在包的导入路径
+// 读取每个文件所在的 go.mod，包括已删除的文件，已删除的 go.mod 从 HEAD 读取
+// go.mod 或 go.sum 的变更会将该模块的所有包标记为已变更
+// 重命名的 Go 文件还会标记其移出的包
+// 传入不带扩展名过滤的匹配选项，以便识别 go.mod 和 go.sum
+// 不评估构建约束，所有 Go 文件都计入
+// 返回按导入路径排序的包
+func (m *ChangedFileManager) ListChangedGoPackages(matchOptions *MatchOptions, packageOptions *PackageOptions) ([]*ChangedPackage, error) {
+	files, err := m.collectChangedFiles(matchOptions, true)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var modules = map[string]*goModule{}
+	var packages = map[string]*ChangedPackage{}
+	var deletedGoMods = map[string]string{}
+	for _, file := range files {
+		if file.IsDeleted() && path.Base(file.RelativePath) == "go.mod" {
+			deletedGoMods[filepath.Dir(file.Path)] = file.RelativePath
+		}
+	}
+	var loadContent = m.lastKnownContent()
+	for _, file := range files {
+		fileName := path.Base(file.RelativePath)
+		if !strings.HasSuffix(fileName, ".go") && fileName != "go.mod" && fileName != "go.sum" {
+			continue
+		}
+		module, err := m.findGoModule(filepath.Dir(file.Path), modules, deletedGoMods, loadContent)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if module == nil {
+			continue // Outside any module // 不属于任何模块
+		}
+
+		if fileName == "go.mod" || fileName == "go.sum" {
+			if err := module.scan(); err != nil {
+				return nil, erero.Wro(err)
+			}
+			for importPath, dir := range module.packageDirs {
+				module.ensurePackage(packages, importPath, dir).ModuleChanged = true
+			}
+			continue
+		}
+		dir := filepath.Dir(file.Path)
+		changedPackage := module.ensurePackage(packages, module.importPathOf(dir), dir)
+		changedPackage.Files = append(changedPackage.Files, file)
+
+		// A file moved in from another package leaves that package changed too
+		// 从其他包移入的文件也使原来的包发生变更
+		if file.IsRenamed() && strings.HasSuffix(file.RenameFrom, ".go") {
+			sourceDIR := filepath.Dir(filepath.Join(m.projectPath, filepath.FromSlash(file.RenameFrom)))
+			sourceModule, err := m.findGoModule(sourceDIR, modules, deletedGoMods, loadContent)
+			if err != nil {
+				return nil, erero.Wro(err)
+			}
+			if sourceModule != nil {
+				sourceModule.ensurePackage(packages, sourceModule.importPathOf(sourceDIR), sourceDIR)
+			}
+		}
+	}
+
+	if packageOptions.reverseDeps {
+		if err := expandReverseDeps(modules, packages); err != nil {
+			return nil, erero.Wro(err)
+		}
+	}
+
+	var results = make([]*ChangedPackage, 0, len(packages))
+	for _, changedPackage := range packages {
+		results = append(results, changedPackage)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].ImportPath < results[j].ImportPath
+	})
+	return results, nil
+}
+
+// findGoModule walks up from DIR to the project root looking for go.mod
+// A go.mod deleted in the change set is read from HEAD, so its files stay in the module they left
+// Returns nil without error when no go.mod is found
+//
+// findGoModule 从目录向上遍历至项目根目录查找 go.mod
+// 变更集中已删除的 go.mod 从 HEAD 读取，使其文件仍归属于原来的模块
+// 未找到 go.mod 时返回 nil 且不返回错误
+func (m *ChangedFileManager) findGoModule(dir string, modules map[string]*goModule, deletedGoMods map[string]string, loadContent func(relativePath string) ([]byte, error)) (*goModule, error) {
+	for {
+		if module, ok := modules[dir]; ok {
+			return module, nil
+		}
+		if !isInsideDIR(m.projectPath, dir) {
+			return nil, nil
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		if os.IsNotExist(err) {
+			if relativePath, ok := deletedGoMods[dir]; ok {
+				data, err = loadContent(relativePath)
+			}
+		}
+		if err == nil {
+			modulePath := modfile.ModulePath(data)
+			if modulePath == "" {
+				return nil, erero.Errorf("no module path in %s", filepath.Join(dir, "go.mod"))
+			}
+			module := &goModule{dir: dir, path: modulePath}
+			modules[dir] = module
+			return module, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, erero.Wro(err)
+		}
+		if parent := filepath.Dir(dir); parent != dir {
+			dir = parent
+		} else {
+			return nil, nil
+		}
+	}
+}
+
+// isInsideDIR checks if path is root or below it, comparing whole path segments
+//
+// isInsideDIR 检查 path 是否为 root 或位于其下，按完整路径段比较
+func isInsideDIR(root string, path string) bool {
+	relativePath, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// goModule holds the packages and import graph of one module, scanned on demand
+//
+// goModule 保存单个模块的包和导入图，按需扫描
+type goModule struct {
+	dir         string              // Absolute module root DIR // 模块根目录的绝对路径
+	path        string              // Module path from go.mod // go.mod 中的模块路径
+	scanned     bool                // Whether packages were scanned // 是否已扫描包
+	packageDirs map[string]string   // Import path to absolute DIR // 导入路径到绝对目录
+	imports     map[string][]string // Import path to imported paths // 导入路径到其导入的路径
+}
+
+// importPathOf computes the import path of a DIR inside the module
+//
+// importPathOf 计算模块内某目录的导入路径
+func (g *goModule) importPathOf(dir string) string {
+	relativeDir, err := filepath.Rel(g.dir, dir)
+	if err != nil || relativeDir == "." {
+		return g.path
+	}
+	return g.path + "/" + filepath.ToSlash(relativeDir)
+}
+
+// ensurePackage returns the changed package entry, creating it when missing
+//
+// ensurePackage 返回变更包条目，不存在时创建
+func (g *goModule) ensurePackage(packages map[string]*ChangedPackage, importPath string, dir string) *ChangedPackage {
+	if changedPackage, ok := packages[importPath]; ok {
+		return changedPackage
+	}
+	changedPackage := &ChangedPackage{ImportPath: importPath, ModulePath: g.path, Dir: dir}
+	packages[importPath] = changedPackage
+	return changedPackage
+}
+
+// scan walks the module collecting packages and their imports
+// Skips vendor, testdata, hidden and underscore DIRs and nested modules
+//
+// scan 遍历模块以收集包及其导入
+// 跳过 vendor、testdata、隐藏目录、下划线目录以及嵌套模块
+func (g *goModule) scan() error {
+	if g.scanned {
+		return nil
+	}
+	g.packageDirs = map[string]string{}
+	g.imports = map[string][]string{}
+	// The DIR of a deleted module might be gone, leaving no packages
+	// 已删除模块的目录可能已不存在，此时没有包
+	if _, err := os.Stat(g.dir); os.IsNotExist(err) {
+		g.scanned = true
+		return nil
+	}
+	fileSet := token.NewFileSet()
+	err := filepath.WalkDir(g.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return erero.Wro(err)
+		}
+		name := entry.Name()
+		if entry.IsDir() {
+			if path == g.dir {
+				return nil
+			}
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") {
+			return nil
+		}
+		importPath := g.importPathOf(filepath.Dir(path))
+		g.packageDirs[importPath] = filepath.Dir(path)
+		// Keep imports parsed before any syntax problem, broken files should not stop the scan
+		// 保留语法问题之前解析到的导入，损坏的文件不应中止扫描
+		astFile, _ := parser.ParseFile(fileSet, path, nil, parser.ImportsOnly)
+		if astFile == nil {
+			return nil
+		}
+		for _, importSpec := range astFile.Imports {
+			if imported, err := strconv.Unquote(importSpec.Path.Value); err == nil {
+				g.imports[importPath] = append(g.imports[importPath], imported)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return erero.Wro(err)
+	}
+	g.scanned = true
+	return nil
+}
+
+// expandReverseDeps adds packages that transitively import affected packages
+//
+// expandReverseDeps 添加传递性导入受影响包的包
+func expandReverseDeps(modules map[string]*goModule, packages map[string]*ChangedPackage) error {
+	for _, module := range modules {
+		if err := module.scan(); err != nil {
+			return erero.Wro(err)
+		}
+		var importers = map[string][]string{}
+		for importer, importedPaths := range module.imports {
+			for _, imported := range importedPaths {
+				importers[imported] = append(importers[imported], importer)
+			}
+		}
+
+		var queue []string
+		for importPath, changedPackage := range packages {
+			if changedPackage.ModulePath == module.path {
+				queue = append(queue, importPath)
+			}
+		}
+		for len(queue) > 0 {
+			importPath := queue[0]
+			queue = queue[1:]
+			for _, importer := range importers[importPath] {
+				if _, ok := packages[importer]; ok {
+					continue
+				}
+				module.ensurePackage(packages, importer, module.packageDirs[importer]).ReverseDependency = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+	return nil
+}
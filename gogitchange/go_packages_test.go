@@ -0,0 +1,154 @@
+package gogitchange_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
+)
+
+// setupGoModuleRepo creates a temp repo holding a small module with an import chain
+// Chain: cmd/app -> c -> b -> a, while d stands alone
+//
+// setupGoModuleRepo 创建包含带导入链的小模块的临时仓库
+// 导入链：cmd/app -> c -> b -> a，d 独立存在
+func setupGoModuleRepo(t *testing.T) (string, *gogitchange.ChangedFileManager) {
+	root, _, tree := setupTestRepo(t, map[string]string{
+		"go.mod":          "module example.com/demo\n\ngo 1.25\n",
+		"a/a.go":          "package a\n\nfunc A() int { return 1 }\n",
+		"b/b.go":          "package b\n\nimport \"example.com/demo/a\"\n\nfunc B() int { return a.A() }\n",
+		"c/c.go":          "package c\n\nimport \"example.com/demo/b\"\n\nfunc C() int { return b.B() }\n",
+		"cmd/app/main.go": "package main\n\nimport \"example.com/demo/c\"\n\nfunc main() { _ = c.C() }\n",
+		"d/d.go":          "package d\n",
+		"README.md":       "# Demo\n",
+	})
+	return root, gogitchange.NewChangedFileManager(root, tree)
+}
+
+// importPathsOf extracts import paths of changed packages
+//
+// importPathsOf 提取变更包的导入路径
+func importPathsOf(packages []*gogitchange.ChangedPackage) []string {
+	var importPaths = make([]string, 0, len(packages))
+	for _, changedPackage := range packages {
+		importPaths = append(importPaths, changedPackage.ImportPath)
+	}
+	return importPaths
+}
+
+// TestChangedFileManager_ListChangedGoPackages verifies mapping of changed files to packages
+// Should include deleted files and skip non-Go files
+//
+// TestChangedFileManager_ListChangedGoPackages 验证变更文件到包的映射
+// 应该包含已删除文件并跳过非 Go 文件
+func TestChangedFileManager_ListChangedGoPackages(t *testing.T) {
+	root, manager := setupGoModuleRepo(t)
+
+	writeTestFile(root, "a/a.go", "package a\n\nfunc A() int { return 2 }\n")
+	must.Done(os.Remove(filepath.Join(root, "d/d.go")))
+	writeTestFile(root, "README.md", "# Changed\n")
+
+	packages, err := manager.ListChangedGoPackages(gogitchange.NewMatchOptions(), gogitchange.NewPackageOptions())
+	require.NoError(t, err)
+	t.Log(neatjsons.S(packages))
+
+	require.Equal(t, []string{"example.com/demo/a", "example.com/demo/d"}, importPathsOf(packages))
+	require.Equal(t, "example.com/demo", packages[0].ModulePath)
+	require.Equal(t, filepath.Join(root, "a"), packages[0].Dir)
+	require.Len(t, packages[1].Files, 1)
+	require.Equal(t, "d/d.go", packages[1].Files[0].RelativePath)
+}
+
+// TestChangedFileManager_ListChangedGoPackages_ReverseDeps verifies expansion to importers
+// Should include transitive importers and flag them as reverse dependencies
+//
+// TestChangedFileManager_ListChangedGoPackages_ReverseDeps 验证向导入方的扩展
+// 应该包含传递性导入方并将其标记为反向依赖
+func TestChangedFileManager_ListChangedGoPackages_ReverseDeps(t *testing.T) {
+	root, manager := setupGoModuleRepo(t)
+
+	writeTestFile(root, "b/b.go", "package b\n\nimport \"example.com/demo/a\"\n\nfunc B() int { return a.A() + 1 }\n")
+
+	packages, err := manager.ListChangedGoPackages(gogitchange.NewMatchOptions(), gogitchange.NewPackageOptions().ReverseDeps(true))
+	require.NoError(t, err)
+	t.Log(neatjsons.S(packages))
+
+	require.Equal(t, []string{"example.com/demo/b", "example.com/demo/c", "example.com/demo/cmd/app"}, importPathsOf(packages))
+	require.False(t, packages[0].ReverseDependency)
+	require.True(t, packages[1].ReverseDependency)
+	require.True(t, packages[2].ReverseDependency)
+}
+
+// TestChangedFileManager_ListChangedGoPackages_GoMod verifies go.mod changes affect the whole module
+//
+// TestChangedFileManager_ListChangedGoPackages_GoMod 验证 go.mod 变更影响整个模块
+func TestChangedFileManager_ListChangedGoPackages_GoMod(t *testing.T) {
+	root, manager := setupGoModuleRepo(t)
+
+	writeTestFile(root, "go.mod", "module example.com/demo\n\ngo 1.25.0\n")
+
+	packages, err := manager.ListChangedGoPackages(gogitchange.NewMatchOptions(), gogitchange.NewPackageOptions())
+	require.NoError(t, err)
+
+	require.Len(t, packages, 5)
+	for _, changedPackage := range packages {
+		require.True(t, changedPackage.ModuleChanged)
+	}
+}
+
+// TestChangedFileManager_ListChangedGoPackages_DeletedGoMod verifies a deleted module keeps its module path
+// The go.mod is gone from disk, so it must be read from HEAD instead of falling back to the parent module
+//
+// TestChangedFileManager_ListChangedGoPackages_DeletedGoMod 验证已删除的模块保留其模块路径
+// go.mod 已不在磁盘上，因此必须从 HEAD 读取，而不是回退到父模块
+func TestChangedFileManager_ListChangedGoPackages_DeletedGoMod(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{
+		"go.mod":           "module example.com/demo\n\ngo 1.25\n",
+		"a/a.go":           "package a\n",
+		"tools/go.mod":     "module example.com/tools\n\ngo 1.25\n",
+		"tools/gen/gen.go": "package gen\n",
+	})
+	must.Done(os.RemoveAll(filepath.Join(root, "tools")))
+
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	packages, err := manager.ListChangedGoPackages(gogitchange.NewMatchOptions(), gogitchange.NewPackageOptions().ReverseDeps(true))
+	require.NoError(t, err)
+	t.Log(neatjsons.S(packages))
+
+	require.Equal(t, []string{"example.com/tools/gen"}, importPathsOf(packages))
+	require.Equal(t, "example.com/tools", packages[0].ModulePath)
+	require.Len(t, packages[0].Files, 1)
+}
+
+// TestChangedFileManager_ListChangedGoPackages_Rename verifies a file moved between packages marks both
+// The source package lost a file, so it and its importers must be listed too
+//
+// TestChangedFileManager_ListChangedGoPackages_Rename 验证在包之间移动的文件会标记两个包
+// 源包失去了一个文件，因此它及其导入方也必须被列出
+func TestChangedFileManager_ListChangedGoPackages_Rename(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{
+		"go.mod":          "module example.com/demo\n\ngo 1.25\n",
+		"a/a.go":          "package a\n\nfunc A() int { return 1 }\n",
+		"a/helper.go":     "package a\n\n// Helper is moved to package d by the test\nfunc Helper() int { return 2 }\n",
+		"b/b.go":          "package b\n\nimport \"example.com/demo/a\"\n\nfunc B() int { return a.A() }\n",
+		"d/d.go":          "package d\n",
+		"cmd/app/main.go": "package main\n\nimport \"example.com/demo/b\"\n\nfunc main() { _ = b.B() }\n",
+	})
+	must.Done(os.Rename(filepath.Join(root, "a/helper.go"), filepath.Join(root, "d/helper.go")))
+
+	manager := gogitchange.NewChangedFileManager(root, tree).WithRenameDetection(gogitchange.NewRenameOptions())
+	packages, err := manager.ListChangedGoPackages(gogitchange.NewMatchOptions(), gogitchange.NewPackageOptions().ReverseDeps(true))
+	require.NoError(t, err)
+	t.Log(neatjsons.S(packages))
+
+	require.Equal(t, []string{"example.com/demo/a", "example.com/demo/b", "example.com/demo/cmd/app", "example.com/demo/d"}, importPathsOf(packages))
+	require.Empty(t, packages[0].Files)
+	require.False(t, packages[0].ReverseDependency)
+	require.True(t, packages[1].ReverseDependency)
+	require.Len(t, packages[3].Files, 1)
+	require.Equal(t, "a/helper.go", packages[3].Files[0].RenameFrom)
+}
@@ -1,20 +1,56 @@
 package gogitchange
 
 import (
+	"path/filepath"
+	"slices"
+	"strings"
+
 	"github.com/go-git/go-git/v5"
+	"github.com/yyle88/erero"
+)
+
+// MatchMode decides how include rules combine
+// MatchAny passes a file when one rule matches, MatchAll needs every rule to match
+//
+// MatchMode 决定包含规则的组合方式
+// MatchAny 在任一规则匹配时通过，MatchAll 需要所有规则都匹配
+type MatchMode string
+
+const (
+	MatchAny MatchMode = "any" // OR semantics // 或语义
+	MatchAll MatchMode = "all" // AND semantics // 与语义
 )
 
 // MatchOptions configures file matching criteria for changed file processing
 // Provides flexible filtering by file extension, path, and file status
+// Criteria of different kinds combine with AND, exclude rules win over include rules
 // Supports fluent configuration pattern for convenient setup
 //
 // MatchOptions 配置用于变更文件处理的文件匹配条件
 // 提供通过文件扩展名、路径和文件状态的灵活过滤
+// 不同类型的条件以“与”组合，排除规则优先于包含规则
 // 支持流畅配置模式以便于设置
 type MatchOptions struct {
-	matchType     string            // File extension screen like ".go", ".txt" // 文件扩展名过滤器，如 ".go", ".txt"
-	matchPath     func(string) bool // Custom path matching function // 自定义路径匹配函数
-	matchStatuses []git.StatusCode  // File status codes to match // 要匹配的文件状态码
+	matchTypes    []string                     // File extension screens like ".go", ".txt" // 文件扩展名过滤器，如 ".go", ".txt"
+	matchPath     func(string) bool            // Custom path matching function // 自定义路径匹配函数
+	matchStatuses []git.StatusCode             // File status codes to match // 要匹配的文件状态码
+	includeRules  []*MatchRule                 // Rules a file must satisfy // 文件必须满足的规则
+	excludeRules  []*MatchRule                 // Rules screening out files // 排除文件的规则
+	includeMode   MatchMode                    // Combination of include rules // 包含规则的组合方式
+	onDeleted     func(file DeletedFile) error // Receives deleted files, nil skips them // 接收已删除的文件，为 nil 时跳过
+}
+
+// MatchConfig is the serializable form of MatchOptions, suitable for config files
+// Rules use the text form accepted by ParseMatchRule
+//
+// MatchConfig 是 MatchOptions 的可序列化形式，适用于配置文件
+// 规则使用 ParseMatchRule 可接受的文本形式
+type MatchConfig struct {
+	Extensions  []string  `json:"extensions,omitempty" yaml:"extensions,omitempty"`     // File extensions // 文件扩展名
+	Include     []string  `json:"include,omitempty" yaml:"include,omitempty"`           // Include rule texts // 包含规则文本
+	Exclude     []string  `json:"exclude,omitempty" yaml:"exclude,omitempty"`           // Exclude rule texts // 排除规则文本
+	IncludeMode MatchMode `json:"include_mode,omitempty" yaml:"include_mode,omitempty"` // "any" (default) or "all" // "any"（默认）或 "all"
+	Statuses    []string  `json:"statuses,omitempty" yaml:"statuses,omitempty"`         // Status codes like "M", "A", "?" // 状态码，如 "M"、"A"、"?"
 }
 
 // NewMatchOptions creates a new instance with default blank matching criteria
@@ -36,7 +72,45 @@ func NewMatchOptions() *MatchOptions {
 // 支持按扩展名过滤文件，如 ".go", ".txt", ".md"
 // 支持流畅配置模式以启用方法链式调用
 func (m *MatchOptions) MatchType(fileExtension string) *MatchOptions {
-	m.matchType = fileExtension
+	m.matchTypes = []string{fileExtension}
+	return m
+}
+
+// MatchTypes sets many file extension screens and returns updated MatchOptions
+// Files pass when the extension equals any of the given ones
+//
+// MatchTypes 设置多个文件扩展名过滤器并返回更新的 MatchOptions
+// 当扩展名等于任一给定扩展名时文件通过
+func (m *MatchOptions) MatchTypes(fileExtensions ...string) *MatchOptions {
+	m.matchTypes = fileExtensions
+	return m
+}
+
+// Include appends rules a file must satisfy and returns updated MatchOptions
+// Rules combine using the include mode, which defaults to MatchAny
+//
+// Include 追加文件必须满足的规则并返回更新的 MatchOptions
+// 规则按包含模式组合，默认为 MatchAny
+func (m *MatchOptions) Include(rules ...*MatchRule) *MatchOptions {
+	m.includeRules = append(m.includeRules, rules...)
+	return m
+}
+
+// Exclude appends rules screening out files and returns updated MatchOptions
+// A file matching any exclude rule is skipped even when include rules pass
+//
+// Exclude 追加排除文件的规则并返回更新的 MatchOptions
+// 匹配任一排除规则的文件会被跳过，即使包含规则通过
+func (m *MatchOptions) Exclude(rules ...*MatchRule) *MatchOptions {
+	m.excludeRules = append(m.excludeRules, rules...)
+	return m
+}
+
+// IncludeMode sets how include rules combine and returns updated MatchOptions
+//
+// IncludeMode 设置包含规则的组合方式并返回更新的 MatchOptions
+func (m *MatchOptions) IncludeMode(mode MatchMode) *MatchOptions {
+	m.includeMode = mode
 	return m
 }
 
@@ -64,6 +138,18 @@ func (m *MatchOptions) MatchStatus(statuses ...git.StatusCode) *MatchOptions {
 	return m
 }
 
+// OnDeleted sets a callback receiving deleted files and returns updated MatchOptions
+// Without it deleted files are skipped, with it they go to this callback instead of the process function
+// Deleted files still pass through status and path screens, keep git.Deleted when using MatchStatus
+//
+// OnDeleted 设置接收已删除文件的回调并返回更新的 MatchOptions
+// 未设置时跳过已删除的文件，设置后它们会传给此回调而非处理函数
+// 已删除的文件仍需通过状态和路径过滤，使用 MatchStatus 时请保留 git.Deleted
+func (m *MatchOptions) OnDeleted(process func(file DeletedFile) error) *MatchOptions {
+	m.onDeleted = process
+	return m
+}
+
 // HasStatusMatch checks if the given file status matches any of the configured status codes
 // Returns true if no status filter is set or if the file status matches any configured code
 //
@@ -84,3 +170,93 @@ func (m *MatchOptions) HasStatusMatch(fileStatus *git.FileStatus) bool {
 	}
 	return false
 }
+
+// NewMatchOptionsFromConfig builds MatchOptions from its serializable form
+// Extensions without a leading dot get one, status texts use their first character
+// Returns error when a rule text or the include mode is invalid
+//
+// NewMatchOptionsFromConfig 从可序列化形式构建 MatchOptions
+// 没有前导点的扩展名会补上点，状态文本使用其首字符
+// 当规则文本或包含模式无效时返回错误
+func NewMatchOptionsFromConfig(config *MatchConfig) (*MatchOptions, error) {
+	var options = NewMatchOptions()
+	for _, extension := range config.Extensions {
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		options.matchTypes = append(options.matchTypes, extension)
+	}
+	includeRules, err := ParseMatchRules(config.Include...)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	excludeRules, err := ParseMatchRules(config.Exclude...)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	switch config.IncludeMode {
+	case "", MatchAny, MatchAll:
+	default:
+		return nil, erero.Errorf("unknown include mode %q", config.IncludeMode)
+	}
+	for _, status := range config.Statuses {
+		if status == "" {
+			return nil, erero.New("blank status code")
+		}
+		options.matchStatuses = append(options.matchStatuses, git.StatusCode(status[0]))
+	}
+	return options.Include(includeRules...).Exclude(excludeRules...).IncludeMode(config.IncludeMode), nil
+}
+
+// HasPathMatch checks if the file passes extension, rule and custom path criteria
+// Receives slash separated relative path and absolute path of the file
+//
+// HasPathMatch 检查文件是否通过扩展名、规则和自定义路径条件
+// 接收文件的斜杠分隔相对路径和绝对路径
+func (m *MatchOptions) HasPathMatch(relativePath string, path string) bool {
+	// Screen files by extension if type matching is specified
+	// 如果指定了类型匹配，则按扩展名过滤文件
+	if len(m.matchTypes) > 0 && !slices.Contains(m.matchTypes, filepath.Ext(relativePath)) {
+		return false
+	}
+
+	// Screen out files matching any exclude rule
+	// 过滤掉匹配任一排除规则的文件
+	for _, rule := range m.excludeRules {
+		if rule.Match(relativePath) {
+			return false
+		}
+	}
+
+	// Screen files by include rules combined with the include mode
+	// 按包含模式组合的包含规则过滤文件
+	if len(m.includeRules) > 0 {
+		match := func(rule *MatchRule) bool { return rule.Match(relativePath) }
+		if m.includeMode == MatchAll {
+			if !allMatch(m.includeRules, match) {
+				return false
+			}
+		} else if !slices.ContainsFunc(m.includeRules, match) {
+			return false
+		}
+	}
+
+	// Screen files by path criteria using custom match function
+	// 使用自定义匹配器函数按路径条件过滤文件
+	if m.matchPath != nil && !m.matchPath(path) {
+		return false
+	}
+	return true
+}
+
+// allMatch checks if every rule satisfies the match function
+//
+// allMatch 检查是否每条规则都满足匹配函数
+func allMatch(rules []*MatchRule, match func(rule *MatchRule) bool) bool {
+	for _, rule := range rules {
+		if !match(rule) {
+			return false
+		}
+	}
+	return true
+}
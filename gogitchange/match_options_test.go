@@ -1,11 +1,13 @@
 package gogitchange_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-xlan/gogit/gogitchange"
 	"github.com/stretchr/testify/require"
+	"github.com/yyle88/rese"
 )
 
 // TestMatchOptions_MatchType verifies file extension filtering
@@ -54,3 +56,54 @@ func TestMatchOptions_Chaining(t *testing.T) {
 		MatchStatus(git.Added, git.Modified)
 	require.NotNil(t, options)
 }
+
+// TestMatchOptions_HasPathMatch verifies combining extensions, include and exclude rules
+// Should apply OR semantics by default and AND semantics with MatchAll
+//
+// TestMatchOptions_HasPathMatch 验证扩展名、包含和排除规则的组合
+// 默认应该使用或语义，使用 MatchAll 时使用与语义
+func TestMatchOptions_HasPathMatch(t *testing.T) {
+	options := gogitchange.NewMatchOptions().
+		MatchTypes(".go", ".md").
+		Include(rese.P1(gogitchange.ParseMatchRule("dir:cmd/")), rese.P1(gogitchange.ParseMatchRule("dir:docs/"))).
+		Exclude(rese.P1(gogitchange.ParseMatchRule("glob:**/*_test.go")))
+
+	require.True(t, options.HasPathMatch("cmd/main.go", "/root/cmd/main.go"))
+	require.True(t, options.HasPathMatch("docs/guide.md", "/root/docs/guide.md"))
+	require.False(t, options.HasPathMatch("cmd/main_test.go", "/root/cmd/main_test.go"))
+	require.False(t, options.HasPathMatch("cmd/config.yaml", "/root/cmd/config.yaml"))
+	require.False(t, options.HasPathMatch("internal/a.go", "/root/internal/a.go"))
+
+	options.IncludeMode(gogitchange.MatchAll)
+	require.False(t, options.HasPathMatch("cmd/main.go", "/root/cmd/main.go"))
+}
+
+// TestNewMatchOptionsFromConfig verifies building options from serialized config
+// Should parse extensions, rules and statuses, and reject invalid input
+//
+// TestNewMatchOptionsFromConfig 验证从序列化配置构建选项
+// 应该解析扩展名、规则和状态，并拒绝无效输入
+func TestNewMatchOptionsFromConfig(t *testing.T) {
+	var config gogitchange.MatchConfig
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"extensions": ["go"],
+		"include": ["dir:pkg/", "glob:cmd/**"],
+		"exclude": ["*.pb.go"],
+		"statuses": ["M", "?"]
+	}`), &config))
+
+	options, err := gogitchange.NewMatchOptionsFromConfig(&config)
+	require.NoError(t, err)
+	require.True(t, options.HasPathMatch("pkg/a.go", "/root/pkg/a.go"))
+	require.True(t, options.HasPathMatch("cmd/app/main.go", "/root/cmd/app/main.go"))
+	require.False(t, options.HasPathMatch("pkg/a.pb.go", "/root/pkg/a.pb.go"))
+	require.False(t, options.HasPathMatch("pkg/a.md", "/root/pkg/a.md"))
+	require.True(t, options.HasStatusMatch(&git.FileStatus{Staging: git.Unmodified, Worktree: git.Modified}))
+	require.False(t, options.HasStatusMatch(&git.FileStatus{Staging: git.Added, Worktree: git.Unmodified}))
+
+	_, err = gogitchange.NewMatchOptionsFromConfig(&gogitchange.MatchConfig{Include: []string{"regex:("}})
+	require.Error(t, err)
+
+	_, err = gogitchange.NewMatchOptionsFromConfig(&gogitchange.MatchConfig{IncludeMode: "none"})
+	require.Error(t, err)
+}
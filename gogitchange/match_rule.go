@@ -0,0 +1,138 @@
+package gogitchange
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/yyle88/erero"
+)
+
+// RuleKind names the syntax of a MatchRule pattern
+// Used as the prefix in rule text, such as "glob:**/*_test.go"
+//
+// RuleKind 表示 MatchRule 模式的语法类型
+// 用作规则文本中的前缀，如 "glob:**/*_test.go"
+type RuleKind string
+
+const (
+	RuleExtension RuleKind = "ext"   // File extension like ".go" // 文件扩展名，如 ".go"
+	RuleGlob      RuleKind = "glob"  // Gitignore-style glob like "**/*_test.go" // gitignore 风格的通配符，如 "**/*_test.go"
+	RuleRegexp    RuleKind = "regex" // Regular expression on relative path // 基于相对路径的正则表达式
+	RulePrefix    RuleKind = "dir"   // Directory prefix like "internal/" // 目录前缀，如 "internal/"
+)
+
+// MatchRule is a declarative path rule matched against slash separated relative paths
+// Negated rules match exactly those paths the pattern does not match
+//
+// MatchRule 是与斜杠分隔的相对路径进行匹配的声明式路径规则
+// 取反的规则恰好匹配模式不匹配的路径
+type MatchRule struct {
+	kind    RuleKind                       // Pattern syntax // 模式语法
+	pattern string                         // Raw pattern text // 原始模式文本
+	negate  bool                           // Invert the match result // 反转匹配结果
+	match   func(relativePath string) bool // Compiled matcher // 编译后的匹配函数
+}
+
+// NewMatchRule compiles a pattern of the given kind into a MatchRule
+// Returns error when kind is unknown or the regular expression is invalid
+//
+// NewMatchRule 将给定类型的模式编译为 MatchRule
+// 当类型未知或正则表达式无效时返回错误
+func NewMatchRule(kind RuleKind, pattern string) (*MatchRule, error) {
+	if pattern == "" {
+		return nil, erero.Errorf("blank %s rule pattern", kind)
+	}
+	rule := &MatchRule{kind: kind, pattern: pattern}
+	switch kind {
+	case RuleExtension:
+		rule.match = func(relativePath string) bool {
+			return filepath.Ext(relativePath) == pattern
+		}
+	case RuleGlob:
+		ignorePattern := gitignore.ParsePattern(pattern, nil)
+		rule.match = func(relativePath string) bool {
+			return ignorePattern.Match(strings.Split(relativePath, "/"), false) != gitignore.NoMatch
+		}
+	case RuleRegexp:
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		rule.match = expression.MatchString
+	case RulePrefix:
+		prefix := strings.TrimSuffix(pattern, "/") + "/"
+		rule.match = func(relativePath string) bool {
+			return strings.HasPrefix(relativePath, prefix)
+		}
+	default:
+		return nil, erero.Errorf("unknown rule kind %q", kind)
+	}
+	return rule, nil
+}
+
+// ParseMatchRule parses rule text in form "[!]kind:pattern", suitable for config files
+// Text without a known kind prefix is treated as a glob, a leading "!" negates the rule
+// Examples: "ext:.go", "glob:**/*_test.go", "regex:^cmd/.+", "dir:internal/", "!glob:*.pb.go"
+//
+// ParseMatchRule 解析 "[!]kind:pattern" 形式的规则文本，适用于配置文件
+// 没有已知类型前缀的文本被视为通配符，开头的 "!" 表示取反
+// 示例："ext:.go"、"glob:**/*_test.go"、"regex:^cmd/.+"、"dir:internal/"、"!glob:*.pb.go"
+func ParseMatchRule(text string) (*MatchRule, error) {
+	negate := strings.HasPrefix(text, "!")
+	text = strings.TrimPrefix(text, "!")
+
+	kind, pattern := RuleGlob, text
+	if prefix, rest, found := strings.Cut(text, ":"); found {
+		if slices.Contains([]RuleKind{RuleExtension, RuleGlob, RuleRegexp, RulePrefix}, RuleKind(prefix)) {
+			kind, pattern = RuleKind(prefix), rest
+		}
+	}
+	rule, err := NewMatchRule(kind, pattern)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return rule.Negate(negate), nil
+}
+
+// ParseMatchRules parses many rule texts, failing on the first invalid one
+//
+// ParseMatchRules 解析多条规则文本，遇到第一条无效规则时失败
+func ParseMatchRules(texts ...string) ([]*MatchRule, error) {
+	var rules = make([]*MatchRule, 0, len(texts))
+	for _, text := range texts {
+		rule, err := ParseMatchRule(text)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Negate sets whether the rule result is inverted and returns the rule
+//
+// Negate 设置是否反转规则结果并返回规则
+func (r *MatchRule) Negate(negate bool) *MatchRule {
+	r.negate = negate
+	return r
+}
+
+// Match checks the slash separated relative path against the rule
+//
+// Match 检查斜杠分隔的相对路径是否符合规则
+func (r *MatchRule) Match(relativePath string) bool {
+	return r.match(relativePath) != r.negate
+}
+
+// String returns the rule in the text form accepted by ParseMatchRule
+//
+// String 返回 ParseMatchRule 可接受的文本形式的规则
+func (r *MatchRule) String() string {
+	if r.negate {
+		return "!" + string(r.kind) + ":" + r.pattern
+	}
+	return string(r.kind) + ":" + r.pattern
+}
@@ -0,0 +1,64 @@
+package gogitchange_test
+
+import (
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseMatchRule verifies each rule kind against sample paths
+// Should follow gitignore glob semantics, regex, prefix and extension matching
+//
+// TestParseMatchRule 使用示例路径验证每种规则类型
+// 应该遵循 gitignore 通配符语义、正则、前缀和扩展名匹配
+func TestParseMatchRule(t *testing.T) {
+	testCases := []struct {
+		text    string
+		path    string
+		matched bool
+	}{
+		{"ext:.go", "cmd/main.go", true},
+		{"ext:.go", "README.md", false},
+		{"glob:**/*_test.go", "main_test.go", true},
+		{"glob:**/*_test.go", "pkg/sub/util_test.go", true},
+		{"glob:**/*_test.go", "pkg/util.go", false},
+		{"*.pb.go", "api/v1/demo.pb.go", true},
+		{"glob:docs/*.md", "docs/guide.md", true},
+		{"glob:docs/*.md", "docs/deep/guide.md", false},
+		{"regex:^cmd/.+\\.go$", "cmd/app/main.go", true},
+		{"regex:^cmd/.+\\.go$", "internal/cmd/main.go", false},
+		{"dir:internal/", "internal/service/a.go", true},
+		{"dir:internal", "internals/a.go", false},
+		{"!dir:vendor/", "vendor/x/y.go", false},
+		{"!dir:vendor/", "main.go", true},
+	}
+	for _, tc := range testCases {
+		rule, err := gogitchange.ParseMatchRule(tc.text)
+		require.NoError(t, err)
+		require.Equal(t, tc.matched, rule.Match(tc.path), "%s on %s", tc.text, tc.path)
+	}
+}
+
+// TestParseMatchRule_Invalid verifies errors on broken rule texts
+//
+// TestParseMatchRule_Invalid 验证无效规则文本时返回错误
+func TestParseMatchRule_Invalid(t *testing.T) {
+	_, err := gogitchange.ParseMatchRule("regex:[")
+	require.Error(t, err)
+
+	_, err = gogitchange.ParseMatchRule("ext:")
+	require.Error(t, err)
+
+	_, err = gogitchange.NewMatchRule(gogitchange.RuleKind("unknown"), "x")
+	require.Error(t, err)
+}
+
+// TestMatchRule_String verifies the text form round trips through ParseMatchRule
+//
+// TestMatchRule_String 验证文本形式可以通过 ParseMatchRule 往返转换
+func TestMatchRule_String(t *testing.T) {
+	rule, err := gogitchange.ParseMatchRule("!glob:**/*_test.go")
+	require.NoError(t, err)
+	require.Equal(t, "!glob:**/*_test.go", rule.String())
+}
@@ -0,0 +1,204 @@
+package gogitchange
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/yyle88/erero"
+)
+
+// ParallelOptions configures concurrent processing of changed files
+// Bounds the worker count and chooses between fail-fast and collect-all error modes
+// Supports fluent configuration pattern for convenient setup
+//
+// ParallelOptions 配置变更文件的并发处理
+// 限制工作协程数量，并在快速失败和收集全部错误模式之间选择
+// 支持流畅配置模式以便于设置
+type ParallelOptions struct {
+	workers       int  // Max count of concurrent workers // 最大并发工作协程数
+	collectErrors bool // Keep going and return all failures // 继续执行并返回所有失败
+}
+
+// NewParallelOptions creates parallel options using GOMAXPROCS workers in fail-fast mode
+//
+// NewParallelOptions 创建使用 GOMAXPROCS 个工作协程的快速失败模式并行选项
+func NewParallelOptions() *ParallelOptions {
+	return &ParallelOptions{
+		workers: runtime.GOMAXPROCS(0),
+	}
+}
+
+// Workers sets the max count of concurrent workers and returns updated ParallelOptions
+// Values below 1 are treated as 1, which processes files one by one
+//
+// Workers 设置最大并发工作协程数并返回更新的 ParallelOptions
+// 小于 1 的值按 1 处理，即逐个处理文件
+func (o *ParallelOptions) Workers(workers int) *ParallelOptions {
+	o.workers = max(workers, 1)
+	return o
+}
+
+// CollectErrors enables the collect-all mode and returns updated ParallelOptions
+// When enabled, failures do not stop other files and come back together as FileErrors
+//
+// CollectErrors 启用收集全部错误模式并返回更新的 ParallelOptions
+// 启用后，失败不会中止其他文件，并以 FileErrors 的形式统一返回
+func (o *ParallelOptions) CollectErrors(enable bool) *ParallelOptions {
+	o.collectErrors = enable
+	return o
+}
+
+// FileError records the failure of processing one changed file
+//
+// FileError 记录处理单个变更文件时的失败
+type FileError struct {
+	File ChangedFile // File being processed // 正在处理的文件
+	Err  error       // Failure returned by process function // 处理函数返回的失败
+}
+
+// Error returns the relative path together with the failure message
+//
+// Error 返回相对路径以及失败消息
+func (e *FileError) Error() string {
+	return e.File.RelativePath + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying failure
+//
+// Unwrap 返回底层失败
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// FileErrors aggregates failures of many files, in the order files were dispatched
+//
+// FileErrors 聚合多个文件的失败，按文件分发的顺序排列
+type FileErrors []*FileError
+
+// Error returns one failure per line
+//
+// Error 每行返回一个失败
+func (es FileErrors) Error() string {
+	var lines = make([]string, 0, len(es))
+	for _, e := range es {
+		lines = append(lines, e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the failures to support errors.Is and errors.As
+//
+// Unwrap 返回各个失败以支持 errors.Is 和 errors.As
+func (es FileErrors) Unwrap() []error {
+	var errs = make([]error, 0, len(es))
+	for _, e := range es {
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+// ForeachParallel processes changed file paths concurrently using a bounded worker pool
+// Same screening as Foreach, see ForeachChangeParallel about ordering and errors
+//
+// ForeachParallel 使用有界工作池并发处理变更文件路径
+// 过滤规则与 Foreach 相同，关于顺序和错误参见 ForeachChangeParallel
+func (m *ChangedFileManager) ForeachParallel(matchOptions *MatchOptions, parallelOptions *ParallelOptions, process func(path string) error) error {
+	if err := m.ForeachChangeParallel(matchOptions, parallelOptions, func(file ChangedFile) error {
+		return process(file.Path)
+	}); err != nil {
+		return erero.Wro(err)
+	}
+	return nil
+}
+
+// ForeachChangeParallel processes changed files concurrently using a bounded worker pool
+// Files are dispatched in the configured sort order so runs are reproducible
+// Fail-fast mode stops dispatching after a failure and returns the first failure in that order
+// Collect mode processes every file and returns all failures as FileErrors in that order
+// Deleted files go to the MatchOptions OnDeleted callback when one is set, one by one on the calling goroutine
+// before the workers start, and their failures come ahead of the others
+// Nil parallel options mean NewParallelOptions
+//
+// ForeachChangeParallel 使用有界工作池并发处理变更文件
+// 文件按配置的排序顺序分发，使多次运行结果可复现
+// 快速失败模式在失败后停止分发，并返回该顺序中的第一个失败
+// 收集模式处理所有文件，并以按该顺序排列的 FileErrors 返回全部失败
+// 设置了 MatchOptions 的 OnDeleted 回调时，已删除的文件在工作协程启动前于调用方协程中逐个传给该回调，
+// 其失败排在其他失败之前
+// parallelOptions 为 nil 时使用 NewParallelOptions
+func (m *ChangedFileManager) ForeachChangeParallel(matchOptions *MatchOptions, parallelOptions *ParallelOptions, process func(file ChangedFile) error) error {
+	if parallelOptions == nil {
+		parallelOptions = NewParallelOptions()
+	}
+	changedFiles, err := m.collectChangedFiles(matchOptions, matchOptions.onDeleted != nil)
+	if err != nil {
+		return erero.Wro(err)
+	}
+
+	// Deliver deleted files one by one, so the callback and content loader need no locking
+	// 逐个传递已删除的文件，使回调和内容加载器无需加锁
+	var fileErrors FileErrors
+	var files = make([]ChangedFile, 0, len(changedFiles))
+	var loadContent = m.lastKnownContent()
+	for _, file := range changedFiles {
+		if matchOptions.onDeleted != nil && file.IsDeleted() {
+			if err := matchOptions.onDeleted(DeletedFile{ChangedFile: file, load: loadContent}); err != nil {
+				if !parallelOptions.collectErrors {
+					return erero.Wro(&FileError{File: file, Err: err})
+				}
+				fileErrors = append(fileErrors, &FileError{File: file, Err: err})
+			}
+			continue
+		}
+		// Skip missing files kept just to find deletions
+		// 跳过仅为查找删除而保留的缺失文件
+		if file.missing {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	var failures = make([]*FileError, len(files))
+	var stopped atomic.Bool
+	var indexes = make(chan int)
+	var wg sync.WaitGroup
+	// Zero-value options have no workers set, clamp so at least one drains the channel
+	// 零值选项未设置工作协程数，限制为至少一个以便消费通道
+	for range min(max(parallelOptions.workers, 1), max(len(files), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				if err := process(files[idx]); err != nil {
+					failures[idx] = &FileError{File: files[idx], Err: err}
+					if !parallelOptions.collectErrors {
+						stopped.Store(true)
+					}
+				}
+			}
+		}()
+	}
+	for idx := range files {
+		if stopped.Load() {
+			break
+		}
+		indexes <- idx
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, failure := range failures {
+		if failure != nil {
+			fileErrors = append(fileErrors, failure)
+		}
+	}
+	if len(fileErrors) == 0 {
+		return nil
+	}
+	if !parallelOptions.collectErrors {
+		return erero.Wro(fileErrors[0])
+	}
+	return erero.Wro(fileErrors)
+}
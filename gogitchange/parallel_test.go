@@ -0,0 +1,126 @@
+package gogitchange_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+)
+
+// setupParallelRepo creates a temp repo with count untracked Go files
+//
+// setupParallelRepo 创建包含 count 个未跟踪 Go 文件的临时仓库
+func setupParallelRepo(t *testing.T, count int) *gogitchange.ChangedFileManager {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	for idx := range count {
+		writeTestFile(root, fmt.Sprintf("pkg/file%02d.go", idx), "package pkg\n")
+	}
+	return gogitchange.NewChangedFileManager(root, tree)
+}
+
+// TestChangedFileManager_ForeachChangeParallel verifies bounded concurrency
+// Should process every file once while never exceeding the worker limit
+//
+// TestChangedFileManager_ForeachChangeParallel 验证有界并发
+// 应该每个文件处理一次，且从不超过工作协程上限
+func TestChangedFileManager_ForeachChangeParallel(t *testing.T) {
+	manager := setupParallelRepo(t, 12)
+
+	var mutex sync.Mutex
+	var processed []string
+	var running, peak atomic.Int32
+	parallelOptions := gogitchange.NewParallelOptions().Workers(3)
+	require.NoError(t, manager.ForeachChangeParallel(gogitchange.NewMatchOptions().MatchType(".go"), parallelOptions, func(file gogitchange.ChangedFile) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := peak.Load()
+			if current <= previous || peak.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		processed = append(processed, file.RelativePath)
+		return nil
+	}))
+
+	require.Len(t, processed, 12)
+	require.LessOrEqual(t, peak.Load(), int32(3))
+	sort.Strings(processed)
+	require.Equal(t, "pkg/file00.go", processed[0])
+}
+
+// TestChangedFileManager_ForeachChangeParallel_CollectErrors verifies error aggregation
+// Should keep going after failures and return them sorted by path
+//
+// TestChangedFileManager_ForeachChangeParallel_CollectErrors 验证错误聚合
+// 失败后应该继续执行，并按路径排序返回失败
+func TestChangedFileManager_ForeachChangeParallel_CollectErrors(t *testing.T) {
+	manager := setupParallelRepo(t, 8)
+
+	var count atomic.Int32
+	parallelOptions := gogitchange.NewParallelOptions().Workers(4).CollectErrors(true)
+	err := manager.ForeachParallel(gogitchange.NewMatchOptions(), parallelOptions, func(path string) error {
+		count.Add(1)
+		if strings.HasSuffix(path, "file05.go") || strings.HasSuffix(path, "file01.go") {
+			return errors.New("broken")
+		}
+		return nil
+	})
+	require.Error(t, err)
+	t.Log(err)
+	require.Equal(t, int32(8), count.Load())
+
+	var fileErrors gogitchange.FileErrors
+	require.True(t, errors.As(err, &fileErrors))
+	require.Len(t, fileErrors, 2)
+	require.Equal(t, "pkg/file01.go", fileErrors[0].File.RelativePath)
+	require.Equal(t, "pkg/file05.go", fileErrors[1].File.RelativePath)
+}
+
+// TestChangedFileManager_ForeachChangeParallel_FailFast verifies fail-fast mode
+// Should return a single FileError when processing fails
+//
+// TestChangedFileManager_ForeachChangeParallel_FailFast 验证快速失败模式
+// 处理失败时应该返回单个 FileError
+func TestChangedFileManager_ForeachChangeParallel_FailFast(t *testing.T) {
+	manager := setupParallelRepo(t, 8)
+
+	err := manager.ForeachChangeParallel(gogitchange.NewMatchOptions(), gogitchange.NewParallelOptions().Workers(1), func(file gogitchange.ChangedFile) error {
+		if file.RelativePath == "pkg/file02.go" {
+			return errors.New("broken")
+		}
+		return nil
+	})
+	require.Error(t, err)
+
+	var fileError *gogitchange.FileError
+	require.True(t, errors.As(err, &fileError))
+	require.Equal(t, "pkg/file02.go", fileError.File.RelativePath)
+}
+
+// TestChangedFileManager_ForeachChangeParallel_ZeroOptions verifies nil and zero-value options still process every file
+//
+// TestChangedFileManager_ForeachChangeParallel_ZeroOptions 验证 nil 和零值选项仍会处理所有文件
+func TestChangedFileManager_ForeachChangeParallel_ZeroOptions(t *testing.T) {
+	manager := setupParallelRepo(t, 4)
+
+	for _, parallelOptions := range []*gogitchange.ParallelOptions{nil, {}} {
+		var count atomic.Int32
+		require.NoError(t, manager.ForeachChangeParallel(gogitchange.NewMatchOptions(), parallelOptions, func(file gogitchange.ChangedFile) error {
+			count.Add(1)
+			return nil
+		}))
+		require.Equal(t, int32(4), count.Load())
+	}
+}
@@ -0,0 +1,187 @@
+package gogitchange
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/formatgo"
+)
+
+// Processor rewrites one changed file in place
+// Receives the file descriptor, the file is at file.Path on disk
+//
+// Processor 原地重写单个变更文件
+// 接收文件描述，文件位于磁盘上的 file.Path
+type Processor func(file ChangedFile) error
+
+// Pipeline runs named processors over changed files, keyed by match rules
+// Processors run in registration order, each on the files its match options accept
+// Supports fluent configuration pattern for convenient setup
+//
+// Pipeline 在变更文件上运行按匹配规则注册的命名处理器
+// 处理器按注册顺序运行，各自处理其匹配选项接受的文件
+// 支持流畅配置模式以便于设置
+type Pipeline struct {
+	steps     []*pipelineStep // Registered processors // 已注册的处理器
+	autoStage bool            // Stage files modified by processors // 暂存被处理器修改的文件
+}
+
+// pipelineStep is one named processor with its match options
+//
+// pipelineStep 是一个带匹配选项的命名处理器
+type pipelineStep struct {
+	name         string        // Processor name shown in reports // 报告中显示的处理器名称
+	matchOptions *MatchOptions // Files this processor handles // 此处理器处理的文件
+	processor    Processor     // Processing function // 处理函数
+}
+
+// NewPipeline creates a blank pipeline without processors
+//
+// NewPipeline 创建不含处理器的空流水线
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Register adds a named processor for files accepted by match options and returns the pipeline
+//
+// Register 为匹配选项接受的文件添加命名处理器并返回流水线
+func (p *Pipeline) Register(name string, matchOptions *MatchOptions, processor Processor) *Pipeline {
+	p.steps = append(p.steps, &pipelineStep{name: name, matchOptions: matchOptions, processor: processor})
+	return p
+}
+
+// AutoStage sets whether modified files get staged after processing and returns the pipeline
+// Needs a manager backed by a worktree
+//
+// AutoStage 设置处理后是否暂存被修改的文件并返回流水线
+// 需要由工作树支持的管理器
+func (p *Pipeline) AutoStage(enable bool) *Pipeline {
+	p.autoStage = enable
+	return p
+}
+
+// ProcessedFile reports what the pipeline did with one file
+//
+// ProcessedFile 报告流水线对单个文件所做的处理
+type ProcessedFile struct {
+	File       ChangedFile // File descriptor before processing // 处理前的文件描述
+	Processors []string    // Processors that ran on the file // 在文件上运行的处理器
+	ModifiedBy []string    // Processors that changed the content // 修改了内容的处理器
+	Staged     bool        // Whether the file got staged // 文件是否已被暂存
+}
+
+// PipelineReport collects the results of one pipeline run, in delivery order
+//
+// PipelineReport 收集一次流水线运行的结果，按传递顺序排列
+type PipelineReport struct {
+	Files []*ProcessedFile // Files handled by one processor at least // 至少被一个处理器处理的文件
+}
+
+// ModifiedFiles returns files whose content was changed by processors
+//
+// ModifiedFiles 返回内容被处理器修改的文件
+func (r *PipelineReport) ModifiedFiles() []ChangedFile {
+	var files = make([]ChangedFile, 0, len(r.Files))
+	for _, processed := range r.Files {
+		if len(processed.ModifiedBy) > 0 {
+			files = append(files, processed.File)
+		}
+	}
+	return files
+}
+
+// Run applies the processors to changed files chosen by match options
+// Stops at the first failure and returns it as FileError naming the processor
+//
+// Run 将处理器应用于匹配选项选中的变更文件
+// 在第一个失败时停止，并以标明处理器的 FileError 返回
+func (p *Pipeline) Run(manager *ChangedFileManager, matchOptions *MatchOptions) (*PipelineReport, error) {
+	if p.autoStage && manager.tree == nil {
+		return nil, erero.New("auto stage needs a manager backed by a worktree")
+	}
+	files, err := manager.collectChangedFiles(matchOptions, false)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var report = &PipelineReport{}
+	for _, file := range files {
+		processed, err := p.runFile(file)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if len(processed.Processors) == 0 {
+			continue
+		}
+		if p.autoStage && len(processed.ModifiedBy) > 0 {
+			if _, err := manager.tree.Add(file.RelativePath); err != nil {
+				return nil, erero.Wro(err)
+			}
+			processed.Staged = true
+		}
+		report.Files = append(report.Files, processed)
+	}
+	return report, nil
+}
+
+// runFile runs matching processors on one file, comparing content around each one
+//
+// runFile 在单个文件上运行匹配的处理器，并比较每次处理前后的内容
+func (p *Pipeline) runFile(file ChangedFile) (*ProcessedFile, error) {
+	var processed = &ProcessedFile{File: file}
+	status := &git.FileStatus{Staging: file.Staging, Worktree: file.Worktree}
+	for _, step := range p.steps {
+		if !step.matchOptions.HasStatusMatch(status) || !step.matchOptions.HasPathMatch(file.RelativePath, file.Path) {
+			continue
+		}
+		previous, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if err := step.processor(file); err != nil {
+			return nil, &FileError{File: file, Err: erero.Wrapf(err, "processor %s", step.name)}
+		}
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		processed.Processors = append(processed.Processors, step.name)
+		if !bytes.Equal(previous, content) {
+			processed.ModifiedBy = append(processed.ModifiedBy, step.name)
+		}
+	}
+	return processed, nil
+}
+
+// GoFormatProcessor formats Go source files using formatgo
+//
+// GoFormatProcessor 使用 formatgo 格式化 Go 源文件
+func GoFormatProcessor() Processor {
+	return func(file ChangedFile) error {
+		if err := formatgo.FormatFile(file.Path); err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	}
+}
+
+// LineEndingProcessor converts CRLF line endings into LF, leaving LF files untouched
+//
+// LineEndingProcessor 将 CRLF 换行符转换为 LF，LF 文件保持不变
+func LineEndingProcessor() Processor {
+	return func(file ChangedFile) error {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		if !bytes.Contains(content, []byte("\r\n")) {
+			return nil
+		}
+		if err := os.WriteFile(file.Path, bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n")), file.Mode.Perm()); err != nil {
+			return erero.Wro(err)
+		}
+		return nil
+	}
+}
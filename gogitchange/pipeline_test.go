@@ -0,0 +1,67 @@
+package gogitchange_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/neatjson/neatjsons"
+	"github.com/yyle88/rese"
+)
+
+// TestPipeline_Run verifies processors keyed by match rules with auto staging
+// Should report and stage just the files processors changed
+//
+// TestPipeline_Run 验证按匹配规则注册的处理器以及自动暂存
+// 应该仅报告并暂存被处理器修改的文件
+func TestPipeline_Run(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	writeTestFile(root, "main.go", "package main\nfunc main() {\n}\n")
+	writeTestFile(root, "clean.go", "package main\n")
+	writeTestFile(root, "docs/guide.md", "# Guide\r\nline\r\n")
+
+	pipeline := gogitchange.NewPipeline().
+		Register("gofmt", gogitchange.NewMatchOptions().MatchType(".go"), gogitchange.GoFormatProcessor()).
+		Register("eol", gogitchange.NewMatchOptions().MatchType(".md"), gogitchange.LineEndingProcessor()).
+		AutoStage(true)
+
+	report, err := pipeline.Run(gogitchange.NewChangedFileManager(root, tree), gogitchange.NewMatchOptions())
+	require.NoError(t, err)
+	t.Log(neatjsons.S(report))
+
+	require.Len(t, report.Files, 3)
+	var modified []string
+	for _, file := range report.ModifiedFiles() {
+		modified = append(modified, file.RelativePath)
+	}
+	require.Equal(t, []string{"docs/guide.md", "main.go"}, modified)
+	require.Equal(t, "# Guide\nline\n", string(rese.V1(os.ReadFile(filepath.Join(root, "docs/guide.md")))))
+
+	statusMap := rese.V1(tree.Status())
+	require.Equal(t, git.Added, statusMap["main.go"].Staging)
+	require.Equal(t, git.Added, statusMap["docs/guide.md"].Staging)
+	require.Equal(t, git.Untracked, statusMap["clean.go"].Staging)
+}
+
+// TestPipeline_Run_Failure verifies failures name the processor and the file
+//
+// TestPipeline_Run_Failure 验证失败信息标明处理器和文件
+func TestPipeline_Run_Failure(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	writeTestFile(root, "main.go", "package main\n")
+
+	pipeline := gogitchange.NewPipeline().Register("broken", gogitchange.NewMatchOptions(), func(file gogitchange.ChangedFile) error {
+		return errors.New("boom")
+	})
+	_, err := pipeline.Run(gogitchange.NewChangedFileManager(root, tree), gogitchange.NewMatchOptions())
+	require.Error(t, err)
+
+	var fileError *gogitchange.FileError
+	require.True(t, errors.As(err, &fileError))
+	require.Equal(t, "main.go", fileError.File.RelativePath)
+	require.Contains(t, fileError.Error(), "processor broken")
+}
@@ -0,0 +1,358 @@
+package gogitchange
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/pkg/errors"
+	"github.com/yyle88/erero"
+)
+
+// DetectRenames pairs deleted and added entries in the status map by content similarity
+// Staged deletions pair with staged additions, worktree deletions pair with untracked files
+// Paired targets get Renamed (or Copied) code with Extra holding the source path
+// Rename sources are removed from the map when the other layer shows no change
+//
+// DetectRenames 按内容相似度将状态映射中的删除条目和新增条目配对
+// 暂存区删除与暂存区新增配对，工作树删除与未跟踪文件配对
+// 配对的目标获得 Renamed（或 Copied）状态码，Extra 保存源路径
+// 当另一层无变化时，重命名源条目会从映射中移除
+func DetectRenames(repo *git.Repository, tree *git.Worktree, statusMap git.Status, options *RenameOptions) error {
+	loader, err := newContentLoader(repo, tree)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	for _, layer := range []*statusLayer{stagingLayer(loader), worktreeLayer(loader)} {
+		if err := detectLayerRenames(layer, statusMap, options); err != nil {
+			return erero.Wro(err)
+		}
+	}
+	return nil
+}
+
+// statusLayer describes one side of the status pair (staging or worktree)
+// Provides accessors to read and write the layer code and to load contents
+//
+// statusLayer 描述状态对中的一侧（暂存区或工作树）
+// 提供读写该层状态码以及加载内容的访问函数
+type statusLayer struct {
+	code          func(status *git.FileStatus) *git.StatusCode      // Code of this layer // 本层状态码
+	otherCode     func(status *git.FileStatus) git.StatusCode       // Code of the other layer // 另一层状态码
+	addedCode     git.StatusCode                                    // Code marking a rename target // 标记重命名目标的状态码
+	sourceContent func(relativePath string) ([]byte, bool, error)   // Content before the change // 变更前的内容
+	targetContent func(relativePath string) ([]byte, bool, error)   // Content after the change // 变更后的内容
+	targetStatus  func(status *git.FileStatus, code git.StatusCode) // Marks the target entry // 标记目标条目
+}
+
+// stagingLayer compares HEAD with the index, like "git diff --cached -M"
+//
+// stagingLayer 比较 HEAD 与索引，类似 "git diff --cached -M"
+func stagingLayer(loader *contentLoader) *statusLayer {
+	return &statusLayer{
+		code:          func(status *git.FileStatus) *git.StatusCode { return &status.Staging },
+		otherCode:     func(status *git.FileStatus) git.StatusCode { return status.Worktree },
+		addedCode:     git.Added,
+		sourceContent: loader.headContent,
+		targetContent: loader.indexContent,
+		targetStatus: func(status *git.FileStatus, code git.StatusCode) {
+			status.Staging = code
+		},
+	}
+}
+
+// worktreeLayer compares the index with the worktree, treating untracked files as additions
+//
+// worktreeLayer 比较索引与工作树，将未跟踪文件视为新增
+func worktreeLayer(loader *contentLoader) *statusLayer {
+	return &statusLayer{
+		code:          func(status *git.FileStatus) *git.StatusCode { return &status.Worktree },
+		otherCode:     func(status *git.FileStatus) git.StatusCode { return status.Staging },
+		addedCode:     git.Untracked,
+		sourceContent: loader.indexOrHeadContent,
+		targetContent: loader.worktreeContent,
+		targetStatus: func(status *git.FileStatus, code git.StatusCode) {
+			status.Staging = git.Unmodified
+			status.Worktree = code
+		},
+	}
+}
+
+// detectLayerRenames runs rename and optional copy detection within one layer
+//
+// detectLayerRenames 在单个层内执行重命名以及可选的复制检测
+func detectLayerRenames(layer *statusLayer, statusMap git.Status, options *RenameOptions) error {
+	var deletedPaths, modifiedPaths, addedPaths []string
+	for relativePath, status := range statusMap {
+		switch *layer.code(status) {
+		case git.Deleted:
+			// Skip entries deleted in staging when checking the worktree layer
+			// 检查工作树层时跳过暂存区已删除的条目
+			if layer.otherCode(status) != git.Deleted {
+				deletedPaths = append(deletedPaths, relativePath)
+			}
+		case git.Modified:
+			modifiedPaths = append(modifiedPaths, relativePath)
+		case layer.addedCode:
+			addedPaths = append(addedPaths, relativePath)
+		}
+	}
+	if len(addedPaths) == 0 || (len(deletedPaths) == 0 && !options.detectCopies) {
+		return nil
+	}
+
+	sources, err := loadContents(deletedPaths, layer.sourceContent)
+	if err != nil {
+		return erero.Wro(err)
+	}
+	targets, err := loadContents(addedPaths, layer.targetContent)
+	if err != nil {
+		return erero.Wro(err)
+	}
+
+	// Pair renames first, each source can be consumed once
+	// 先配对重命名，每个源只能被使用一次
+	for _, pair := range pairBySimilarity(sources, targets, options.threshold, true) {
+		layer.targetStatus(statusMap[pair.target], git.Renamed)
+		statusMap[pair.target].Extra = pair.source
+		if layer.otherCode(statusMap[pair.source]) == git.Unmodified {
+			delete(statusMap, pair.source)
+		}
+		delete(targets, pair.target)
+	}
+
+	// Pair copies with remaining targets, sources can be consumed many times
+	// 将剩余目标与复制源配对，源可以被多次使用
+	if options.detectCopies && len(targets) > 0 {
+		modified, err := loadContents(modifiedPaths, layer.sourceContent)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		for relativePath, content := range modified {
+			sources[relativePath] = content
+		}
+		for _, pair := range pairBySimilarity(sources, targets, options.threshold, false) {
+			layer.targetStatus(statusMap[pair.target], git.Copied)
+			statusMap[pair.target].Extra = pair.source
+		}
+	}
+	return nil
+}
+
+// loadContents loads contents of the given paths, skipping paths without content
+//
+// loadContents 加载给定路径的内容，跳过没有内容的路径
+func loadContents(relativePaths []string, load func(relativePath string) ([]byte, bool, error)) (map[string][]byte, error) {
+	var contents = make(map[string][]byte, len(relativePaths))
+	for _, relativePath := range relativePaths {
+		content, exists, err := load(relativePath)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		if exists {
+			contents[relativePath] = content
+		}
+	}
+	return contents, nil
+}
+
+// renamePair represents a matched source and target with similarity score
+//
+// renamePair 代表匹配的源和目标及其相似度分数
+type renamePair struct {
+	source string // Source relative path // 源相对路径
+	target string // Target relative path // 目标相对路径
+	score  int    // Similarity percent // 相似度百分比
+}
+
+// pairBySimilarity matches targets to sources greedily, best score first
+// Ties are broken by path to keep results stable across runs
+//
+// pairBySimilarity 按最高分优先的贪心策略将目标与源匹配
+// 分数相同时按路径排序以保证多次运行结果稳定
+func pairBySimilarity(sources, targets map[string][]byte, threshold int, exclusive bool) []renamePair {
+	var candidates []renamePair
+	for target, targetContent := range targets {
+		for source, sourceContent := range sources {
+			if score := similarityPercent(sourceContent, targetContent); score >= threshold {
+				candidates = append(candidates, renamePair{source: source, target: target, score: score})
+			}
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].target != candidates[j].target {
+			return candidates[i].target < candidates[j].target
+		}
+		return candidates[i].source < candidates[j].source
+	})
+
+	var pairs []renamePair
+	var usedSources = map[string]bool{}
+	var usedTargets = map[string]bool{}
+	for _, pair := range candidates {
+		if usedTargets[pair.target] || (exclusive && usedSources[pair.source]) {
+			continue
+		}
+		usedTargets[pair.target] = true
+		usedSources[pair.source] = true
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// similarityPercent estimates how much of the content survived the change
+// Counts bytes of lines shared by both sides against the larger side
+// Returns 100 just when contents are identical, blank files never match
+//
+// similarityPercent 估算变更后保留了多少内容
+// 统计两侧共有行的字节数占较大一侧的比例
+// 仅当内容完全一致时返回 100，空文件永不匹配
+func similarityPercent(source, target []byte) int {
+	if len(source) == 0 || len(target) == 0 {
+		return 0
+	}
+	if bytes.Equal(source, target) {
+		return 100
+	}
+	var counts = map[string]int{}
+	for _, line := range bytes.SplitAfter(source, []byte("\n")) {
+		counts[string(line)]++
+	}
+	var matched int
+	for _, line := range bytes.SplitAfter(target, []byte("\n")) {
+		if counts[string(line)] > 0 {
+			counts[string(line)]--
+			matched += len(line)
+		}
+	}
+	return min(matched*100/max(len(source), len(target)), 99)
+}
+
+// contentLoader reads file contents from HEAD, the index and the worktree
+//
+// contentLoader 从 HEAD、索引和工作树读取文件内容
+type contentLoader struct {
+	repo     *git.Repository // Repo used to read blobs // 用于读取 blob 的仓库
+	tree     *git.Worktree   // Worktree used to read files on disk // 用于读取磁盘文件的工作树
+	headTree *object.Tree    // HEAD tree, nil in repos without commits // HEAD 树，无提交的仓库中为 nil
+	idx      *index.Index    // Staging index // 暂存区索引
+}
+
+// newContentLoader prepares HEAD tree and index to read contents
+//
+// newContentLoader 准备 HEAD 树和索引以读取内容
+func newContentLoader(repo *git.Repository, tree *git.Worktree) (*contentLoader, error) {
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	headTree, err := loadHeadTree(repo)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return &contentLoader{repo: repo, tree: tree, headTree: headTree, idx: idx}, nil
+}
+
+// loadHeadTree returns the tree of HEAD commit, nil when HEAD is unborn
+//
+// loadHeadTree 返回 HEAD 提交的树，HEAD 尚未产生时返回 nil
+func loadHeadTree(repo *git.Repository) (*object.Tree, error) {
+	head, err := repo.Head()
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, nil
+		}
+		return nil, erero.Wro(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	headTree, err := commit.Tree()
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+	return headTree, nil
+}
+
+// headContent reads file content recorded in HEAD
+//
+// headContent 读取 HEAD 中记录的文件内容
+func (l *contentLoader) headContent(relativePath string) ([]byte, bool, error) {
+	if l.headTree == nil {
+		return nil, false, nil
+	}
+	file, err := l.headTree.File(relativePath)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, erero.Wro(err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	return []byte(content), true, nil
+}
+
+// indexContent reads file content recorded in the staging index
+//
+// indexContent 读取暂存区索引中记录的文件内容
+func (l *contentLoader) indexContent(relativePath string) ([]byte, bool, error) {
+	entry, err := l.idx.Entry(relativePath)
+	if err != nil {
+		if errors.Is(err, index.ErrEntryNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, erero.Wro(err)
+	}
+	blob, err := l.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	defer func() { _ = reader.Close() }()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, erero.Wro(err)
+	}
+	return content, true, nil
+}
+
+// indexOrHeadContent reads content from the index, falling back to HEAD
+//
+// indexOrHeadContent 从索引读取内容，不存在时回退到 HEAD
+func (l *contentLoader) indexOrHeadContent(relativePath string) ([]byte, bool, error) {
+	content, exists, err := l.indexContent(relativePath)
+	if err != nil || exists {
+		return content, exists, err
+	}
+	return l.headContent(relativePath)
+}
+
+// worktreeContent reads file content from the worktree on disk
+//
+// worktreeContent 从磁盘上的工作树读取文件内容
+func (l *contentLoader) worktreeContent(relativePath string) ([]byte, bool, error) {
+	content, err := util.ReadFile(l.tree.Filesystem, relativePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, erero.Wro(err)
+	}
+	return content, true, nil
+}
@@ -0,0 +1,121 @@
+package gogitchange_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/neatjson/neatjsons"
+)
+
+const renameTestContent = "package demo\n\nfunc A() int {\n\treturn 1\n}\n\nfunc B() int {\n\treturn 2\n}\n"
+
+// TestDetectRenames_Worktree verifies pairing of a moved file that is not staged
+// Should fold the deletion and the untracked file into one rename entry
+//
+// TestDetectRenames_Worktree 验证未暂存的移动文件的配对
+// 应该将删除和未跟踪文件合并为一个重命名条目
+func TestDetectRenames_Worktree(t *testing.T) {
+	root, repo, tree := setupTestRepo(t, map[string]string{"old/demo.go": renameTestContent})
+
+	must.Done(os.MkdirAll(filepath.Join(root, "new"), 0755))
+	must.Done(os.Rename(filepath.Join(root, "old/demo.go"), filepath.Join(root, "new/demo.go")))
+
+	statusMap, err := tree.Status()
+	require.NoError(t, err)
+	require.NoError(t, gogitchange.DetectRenames(repo, tree, statusMap, gogitchange.NewRenameOptions()))
+	t.Log(neatjsons.S(statusMap))
+
+	require.Len(t, statusMap, 1)
+	require.Equal(t, git.Renamed, statusMap["new/demo.go"].Worktree)
+	require.Equal(t, "old/demo.go", statusMap["new/demo.go"].Extra)
+}
+
+// TestDetectRenames_Staging verifies pairing of a staged rename with edits
+// Should report Renamed in staging when similarity passes the threshold
+//
+// TestDetectRenames_Staging 验证带修改的已暂存重命名的配对
+// 当相似度超过阈值时应该在暂存区报告 Renamed
+func TestDetectRenames_Staging(t *testing.T) {
+	root, repo, tree := setupTestRepo(t, map[string]string{"demo.go": renameTestContent})
+
+	must.Done(os.Remove(filepath.Join(root, "demo.go")))
+	writeTestFile(root, "demo_renamed.go", renameTestContent+"\nfunc C() int {\n\treturn 3\n}\n")
+	require.NoError(t, tree.AddWithOptions(&git.AddOptions{All: true}))
+
+	statusMap, err := tree.Status()
+	require.NoError(t, err)
+	require.NoError(t, gogitchange.DetectRenames(repo, tree, statusMap, gogitchange.NewRenameOptions()))
+	t.Log(neatjsons.S(statusMap))
+
+	require.Len(t, statusMap, 1)
+	require.Equal(t, git.Renamed, statusMap["demo_renamed.go"].Staging)
+	require.Equal(t, "demo.go", statusMap["demo_renamed.go"].Extra)
+}
+
+// TestDetectRenames_Threshold verifies that dissimilar files are not paired
+// Should keep the deletion and addition when similarity is below the threshold
+//
+// TestDetectRenames_Threshold 验证不相似的文件不会被配对
+// 当相似度低于阈值时应该保留删除和新增
+func TestDetectRenames_Threshold(t *testing.T) {
+	root, repo, tree := setupTestRepo(t, map[string]string{"demo.go": renameTestContent})
+
+	must.Done(os.Remove(filepath.Join(root, "demo.go")))
+	writeTestFile(root, "other.go", "package other\n")
+
+	statusMap, err := tree.Status()
+	require.NoError(t, err)
+	require.NoError(t, gogitchange.DetectRenames(repo, tree, statusMap, gogitchange.NewRenameOptions().Threshold(90)))
+
+	require.Len(t, statusMap, 2)
+	require.Equal(t, git.Deleted, statusMap["demo.go"].Worktree)
+	require.Equal(t, git.Untracked, statusMap["other.go"].Worktree)
+}
+
+// TestDetectRenames_Copies verifies copy detection from a modified file
+// Should report the new file as a copy while keeping the source modified
+//
+// TestDetectRenames_Copies 验证来自已修改文件的复制检测
+// 应该将新文件报告为复制，同时保留源文件的修改状态
+func TestDetectRenames_Copies(t *testing.T) {
+	root, repo, tree := setupTestRepo(t, map[string]string{"demo.go": renameTestContent})
+
+	writeTestFile(root, "demo.go", renameTestContent+"// modified\n")
+	writeTestFile(root, "demo_copy.go", renameTestContent)
+
+	statusMap, err := tree.Status()
+	require.NoError(t, err)
+	require.NoError(t, gogitchange.DetectRenames(repo, tree, statusMap, gogitchange.NewRenameOptions().DetectCopies(true)))
+	t.Log(neatjsons.S(statusMap))
+
+	require.Equal(t, git.Modified, statusMap["demo.go"].Worktree)
+	require.Equal(t, git.Copied, statusMap["demo_copy.go"].Worktree)
+	require.Equal(t, "demo.go", statusMap["demo_copy.go"].Extra)
+}
+
+// TestChangedFileManager_ListChangedFiles_Rename verifies RenameFrom in descriptors
+// Should expose the original path of a moved file to callers
+//
+// TestChangedFileManager_ListChangedFiles_Rename 验证描述中的 RenameFrom
+// 应该向调用方暴露被移动文件的原始路径
+func TestChangedFileManager_ListChangedFiles_Rename(t *testing.T) {
+	root, repo, tree := setupTestRepo(t, map[string]string{"demo.go": renameTestContent})
+
+	must.Done(os.Rename(filepath.Join(root, "demo.go"), filepath.Join(root, "moved.go")))
+
+	manager := gogitchange.NewChangedFileManager(root, tree).WithRepo(repo).WithRenameDetection(gogitchange.NewRenameOptions())
+	files, err := manager.ListChangedFiles(gogitchange.NewMatchOptions().MatchType(".go"))
+	require.NoError(t, err)
+	t.Log(neatjsons.S(files))
+
+	require.Len(t, files, 1)
+	require.Equal(t, "moved.go", files[0].RelativePath)
+	require.Equal(t, filepath.Join(root, "moved.go"), files[0].Path)
+	require.True(t, files[0].IsRenamed())
+	require.Equal(t, "demo.go", files[0].RenameFrom)
+}
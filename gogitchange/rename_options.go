@@ -0,0 +1,51 @@
+package gogitchange
+
+// DefaultRenameThreshold is the similarity percent used when no threshold is configured
+// Matches the default of git CLI, where -M means -M50%
+//
+// DefaultRenameThreshold 是未配置阈值时使用的相似度百分比
+// 与 git 命令行默认值一致，-M 即 -M50%
+const DefaultRenameThreshold = 50
+
+// RenameOptions configures similarity-based rename and copy detection
+// Pairs deleted files with added files when contents are similar enough
+// Supports fluent configuration pattern for convenient setup
+//
+// RenameOptions 配置基于相似度的重命名和复制检测
+// 当内容足够相似时将已删除文件与新增文件配对
+// 支持流畅配置模式以便于设置
+type RenameOptions struct {
+	threshold    int  // Minimum similarity percent (1-100) // 最小相似度百分比（1-100）
+	detectCopies bool // Detect copies from modified files too // 同时检测来自已修改文件的复制
+}
+
+// NewRenameOptions creates rename options with the default threshold
+// Copy detection is disabled unless DetectCopies is invoked
+//
+// NewRenameOptions 使用默认阈值创建重命名选项
+// 除非调用 DetectCopies，否则复制检测处于禁用状态
+func NewRenameOptions() *RenameOptions {
+	return &RenameOptions{
+		threshold: DefaultRenameThreshold,
+	}
+}
+
+// Threshold sets the minimum similarity percent and returns updated RenameOptions
+// Values are clamped into range 1-100, where 100 means exact content match
+//
+// Threshold 设置最小相似度百分比并返回更新的 RenameOptions
+// 值会被限制在 1-100 范围内，100 表示内容完全一致
+func (o *RenameOptions) Threshold(percent int) *RenameOptions {
+	o.threshold = min(max(percent, 1), 100)
+	return o
+}
+
+// DetectCopies enables copy detection and returns updated RenameOptions
+// Added files similar to modified or renamed sources are reported as copies
+//
+// DetectCopies 启用复制检测并返回更新的 RenameOptions
+// 与已修改或被重命名源文件相似的新增文件会被报告为复制
+func (o *RenameOptions) DetectCopies(enable bool) *RenameOptions {
+	o.detectCopies = enable
+	return o
+}
@@ -0,0 +1,65 @@
+package gogitchange
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// SortOrder names the order in which changed files are delivered
+// Every order falls back to relative path, so results are always deterministic
+//
+// SortOrder 表示变更文件的传递顺序
+// 每种顺序都以相对路径兜底，因此结果总是确定的
+type SortOrder string
+
+const (
+	SortByPath   SortOrder = "path"   // Relative path, the default // 相对路径，默认值
+	SortByStatus SortOrder = "status" // Staged changes, then worktree changes, then untracked files // 先暂存区变更，再工作树变更，最后未跟踪文件
+	SortByDepth  SortOrder = "depth"  // Shallow files before nested files // 浅层文件在嵌套文件之前
+)
+
+// stagedStatusRanks lists staging codes in delivery order when sorting by status
+//
+// stagedStatusRanks 列出按状态排序时暂存区状态码的传递顺序
+var stagedStatusRanks = []git.StatusCode{git.Added, git.Renamed, git.Copied, git.Modified, git.UpdatedButUnmerged}
+
+// SortChangedFiles sorts files in place using the given order
+// Blank or unknown orders sort by relative path
+//
+// SortChangedFiles 使用给定顺序原地排序文件
+// 空的或未知的顺序按相对路径排序
+func SortChangedFiles(files []ChangedFile, sortOrder SortOrder) {
+	var rank func(file ChangedFile) int
+	switch sortOrder {
+	case SortByStatus:
+		rank = statusRank
+	case SortByDepth:
+		rank = func(file ChangedFile) int {
+			return strings.Count(file.RelativePath, "/")
+		}
+	default:
+		rank = func(file ChangedFile) int { return 0 }
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		if rankI, rankJ := rank(files[i]), rank(files[j]); rankI != rankJ {
+			return rankI < rankJ
+		}
+		return files[i].RelativePath < files[j].RelativePath
+	})
+}
+
+// statusRank groups staged changes first, then worktree changes, then untracked files
+//
+// statusRank 将暂存区变更排在最前，然后是工作树变更，最后是未跟踪文件
+func statusRank(file ChangedFile) int {
+	if idx := slices.Index(stagedStatusRanks, file.Staging); idx >= 0 {
+		return idx
+	}
+	if file.Worktree == git.Untracked {
+		return len(stagedStatusRanks) + 2
+	}
+	return len(stagedStatusRanks) + 1
+}
@@ -0,0 +1,79 @@
+package gogitchange_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSortChangedFiles verifies each sort order with path as the tie breaker
+//
+// TestSortChangedFiles 验证每种排序方式，并以路径作为平局判定
+func TestSortChangedFiles(t *testing.T) {
+	newFiles := func() []gogitchange.ChangedFile {
+		return []gogitchange.ChangedFile{
+			{RelativePath: "z.go", Staging: git.Untracked, Worktree: git.Untracked},
+			{RelativePath: "a/b/c.go", Staging: git.Unmodified, Worktree: git.Modified},
+			{RelativePath: "a/b.go", Staging: git.Added, Worktree: git.Unmodified},
+			{RelativePath: "m.go", Staging: git.Modified, Worktree: git.Unmodified},
+		}
+	}
+	relativePaths := func(files []gogitchange.ChangedFile) []string {
+		var paths []string
+		for _, file := range files {
+			paths = append(paths, file.RelativePath)
+		}
+		return paths
+	}
+
+	files := newFiles()
+	gogitchange.SortChangedFiles(files, gogitchange.SortByPath)
+	require.Equal(t, []string{"a/b.go", "a/b/c.go", "m.go", "z.go"}, relativePaths(files))
+
+	files = newFiles()
+	gogitchange.SortChangedFiles(files, gogitchange.SortByStatus)
+	require.Equal(t, []string{"a/b.go", "m.go", "a/b/c.go", "z.go"}, relativePaths(files))
+
+	files = newFiles()
+	gogitchange.SortChangedFiles(files, gogitchange.SortByDepth)
+	require.Equal(t, []string{"m.go", "z.go", "a/b.go", "a/b/c.go"}, relativePaths(files))
+}
+
+// TestChangedFileManager_WithSortOrder verifies that iteration follows the configured order
+// Should list paths deterministically across repeated runs
+//
+// TestChangedFileManager_WithSortOrder 验证遍历遵循配置的顺序
+// 多次运行时应该确定性地列出路径
+func TestChangedFileManager_WithSortOrder(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	for _, name := range []string{"x/y/z.go", "b.go", "a/c.go", "a.go"} {
+		writeTestFile(root, name, "package demo\n")
+	}
+
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	for range 3 {
+		paths, err := manager.ListChangedFilePaths(gogitchange.NewMatchOptions())
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			filepath.Join(root, "a.go"),
+			filepath.Join(root, "a/c.go"),
+			filepath.Join(root, "b.go"),
+			filepath.Join(root, "x/y/z.go"),
+		}, paths)
+	}
+
+	var visited []string
+	require.NoError(t, manager.WithSortOrder(gogitchange.SortByDepth).ForeachChangedGoFile(gogitchange.NewMatchOptions(), func(path string) error {
+		visited = append(visited, path)
+		return nil
+	}))
+	require.Equal(t, []string{
+		filepath.Join(root, "a.go"),
+		filepath.Join(root, "b.go"),
+		filepath.Join(root, "a/c.go"),
+		filepath.Join(root, "x/y/z.go"),
+	}, visited)
+}
@@ -0,0 +1,286 @@
+package gogitchange
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/yyle88/erero"
+	"github.com/yyle88/formatgo"
+)
+
+// Transformer computes the new content of a changed file without touching the disk
+// Returning content equal to the input means the file needs no change
+//
+// Transformer 计算变更文件的新内容而不修改磁盘
+// 返回与输入相同的内容表示文件无需修改
+type Transformer func(file ChangedFile, content []byte) ([]byte, error)
+
+// TransformOptions configures how transform results are previewed and applied
+// Supports fluent configuration pattern for convenient setup
+//
+// TransformOptions 配置转换结果的预览和应用方式
+// 支持流畅配置模式以便于设置
+type TransformOptions struct {
+	dryRun       bool // Preview without writing files // 仅预览而不写入文件
+	contextLines int  // Context lines around diff hunks // 差异块周围的上下文行数
+}
+
+// NewTransformOptions creates options that apply changes with 3 context lines in diffs
+//
+// NewTransformOptions 创建应用变更且差异上下文为 3 行的选项
+func NewTransformOptions() *TransformOptions {
+	return &TransformOptions{
+		contextLines: 3,
+	}
+}
+
+// DryRun sets whether changes are just previewed and returns updated TransformOptions
+//
+// DryRun 设置是否仅预览变更并返回更新的 TransformOptions
+func (o *TransformOptions) DryRun(dryRun bool) *TransformOptions {
+	o.dryRun = dryRun
+	return o
+}
+
+// ContextLines sets the count of context lines in diffs and returns updated TransformOptions
+//
+// ContextLines 设置差异中的上下文行数并返回更新的 TransformOptions
+func (o *TransformOptions) ContextLines(contextLines int) *TransformOptions {
+	o.contextLines = max(contextLines, 0)
+	return o
+}
+
+// TransformResult reports the outcome of transforming one file
+//
+// TransformResult 报告单个文件的转换结果
+type TransformResult struct {
+	File    ChangedFile // Transformed file // 被转换的文件
+	Diff    string      // Unified diff preview // 统一差异预览
+	Applied bool        // Whether new content was written // 是否已写入新内容
+}
+
+// TransformReport collects results of files whose content changed, in delivery order
+//
+// TransformReport 收集内容发生变化的文件结果，按传递顺序排列
+type TransformReport struct {
+	Results []*TransformResult // Changed files // 发生变化的文件
+}
+
+// Diff joins the diffs of all results into one patch text
+//
+// Diff 将所有结果的差异合并为一个补丁文本
+func (r *TransformReport) Diff() string {
+	var builder strings.Builder
+	for _, result := range r.Results {
+		builder.WriteString(result.Diff)
+	}
+	return builder.String()
+}
+
+// Transform runs the transformer on changed files and previews or applies the new contents
+// Every changed file gets a unified diff, applied writes are atomic and keep the file mode
+// Stops at the first failure and returns it as FileError
+//
+// Transform 在变更文件上运行转换器，并预览或应用新内容
+// 每个发生变化的文件都会生成统一差异，写入是原子的且保留文件模式
+// 在第一个失败时停止，并以 FileError 返回
+func (m *ChangedFileManager) Transform(matchOptions *MatchOptions, transformOptions *TransformOptions, transformer Transformer) (*TransformReport, error) {
+	files, err := m.collectChangedFiles(matchOptions, false)
+	if err != nil {
+		return nil, erero.Wro(err)
+	}
+
+	var report = &TransformReport{}
+	for _, file := range files {
+		content, err := os.ReadFile(file.Path)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		newContent, err := transformer(file, content)
+		if err != nil {
+			return nil, &FileError{File: file, Err: err}
+		}
+		if bytes.Equal(content, newContent) {
+			continue
+		}
+
+		text, err := UnifiedDiff(file.RelativePath, file.Mode, content, newContent, transformOptions.contextLines)
+		if err != nil {
+			return nil, &FileError{File: file, Err: err}
+		}
+		result := &TransformResult{
+			File: file,
+			Diff: text,
+		}
+		if !transformOptions.dryRun {
+			if err := WriteFileAtomic(file.Path, newContent, file.Mode); err != nil {
+				return nil, &FileError{File: file, Err: err}
+			}
+			result.Applied = true
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// GoFormatTransformer formats Go source content using formatgo
+//
+// GoFormatTransformer 使用 formatgo 格式化 Go 源代码内容
+func GoFormatTransformer() Transformer {
+	return func(file ChangedFile, content []byte) ([]byte, error) {
+		if filepath.Ext(file.Path) != ".go" {
+			return content, nil
+		}
+		newContent, err := formatgo.FormatBytes(content)
+		if err != nil {
+			return nil, erero.Wro(err)
+		}
+		return newContent, nil
+	}
+}
+
+// WriteFileAtomic writes content into a temp file next to path and renames it into place
+// Readers see either the old or the new content, never a partial write
+// The new file gets the given mode, so permissions survive the rewrite
+// A symlink path is written through to its target, so the link stays a link
+//
+// WriteFileAtomic 将内容写入 path 旁的临时文件并重命名到目标位置
+// 读取方只会看到旧内容或新内容，不会看到部分写入
+// 新文件使用给定的模式，因此重写后权限得以保留
+// path 为符号链接时写入其目标，使链接仍然是链接
+func WriteFileAtomic(path string, content []byte, mode os.FileMode) error {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return erero.Wro(err)
+		}
+		path = target
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return erero.Wro(err)
+	}
+	tempPath := temp.Name()
+	// Remove the temp file when anything fails before the rename
+	// 在重命名之前任何步骤失败时删除临时文件
+	success := false
+	defer func() {
+		if !success {
+			_ = os.Remove(tempPath)
+		}
+	}()
+
+	if _, err := temp.Write(content); err != nil {
+		_ = temp.Close()
+		return erero.Wro(err)
+	}
+	if err := temp.Sync(); err != nil {
+		_ = temp.Close()
+		return erero.Wro(err)
+	}
+	if err := temp.Close(); err != nil {
+		return erero.Wro(err)
+	}
+	if err := os.Chmod(tempPath, mode.Perm()); err != nil {
+		return erero.Wro(err)
+	}
+	if err := os.Rename(tempPath, path); err != nil {
+		return erero.Wro(err)
+	}
+	success = true
+	return nil
+}
+
+// UnifiedDiff renders a git style unified diff between two contents of one file
+// Returns blank string when contents are equal, and an error when encoding fails
+//
+// UnifiedDiff 渲染同一文件两份内容之间的 git 风格统一差异
+// 内容相同时返回空字符串，编码失败时返回错误
+func UnifiedDiff(relativePath string, mode os.FileMode, before []byte, after []byte, contextLines int) (string, error) {
+	if bytes.Equal(before, after) {
+		return "", nil
+	}
+	fileMode, err := filemode.NewFromOSFileMode(mode)
+	if err != nil || fileMode == filemode.Empty {
+		fileMode = filemode.Regular
+	}
+
+	var chunks []fdiff.Chunk
+	for _, item := range diff.Do(string(before), string(after)) {
+		chunks = append(chunks, &textChunk{content: item.Text, operation: chunkOperations[item.Type]})
+	}
+	filePatch := &textFilePatch{
+		from:   &textFile{path: relativePath, mode: fileMode, hash: plumbing.ComputeHash(plumbing.BlobObject, before)},
+		to:     &textFile{path: relativePath, mode: fileMode, hash: plumbing.ComputeHash(plumbing.BlobObject, after)},
+		chunks: chunks,
+	}
+
+	var builder strings.Builder
+	if err := fdiff.NewUnifiedEncoder(&builder, contextLines).Encode(&textPatch{filePatches: []fdiff.FilePatch{filePatch}}); err != nil {
+		return "", erero.Wro(err)
+	}
+	return builder.String(), nil
+}
+
+// chunkOperations maps diff-match-patch operations into patch chunk operations
+//
+// chunkOperations 将 diff-match-patch 操作映射为补丁块操作
+var chunkOperations = map[diffmatchpatch.Operation]fdiff.Operation{
+	diffmatchpatch.DiffEqual:  fdiff.Equal,
+	diffmatchpatch.DiffInsert: fdiff.Add,
+	diffmatchpatch.DiffDelete: fdiff.Delete,
+}
+
+// textPatch implements diff.Patch holding in-memory file patches
+//
+// textPatch 实现 diff.Patch，保存内存中的文件补丁
+type textPatch struct {
+	filePatches []fdiff.FilePatch
+}
+
+func (p *textPatch) FilePatches() []fdiff.FilePatch { return p.filePatches }
+func (p *textPatch) Message() string                { return "" }
+
+// textFilePatch implements diff.FilePatch on text contents
+//
+// textFilePatch 基于文本内容实现 diff.FilePatch
+type textFilePatch struct {
+	from   *textFile
+	to     *textFile
+	chunks []fdiff.Chunk
+}
+
+func (p *textFilePatch) IsBinary() bool                  { return false }
+func (p *textFilePatch) Files() (fdiff.File, fdiff.File) { return p.from, p.to }
+func (p *textFilePatch) Chunks() []fdiff.Chunk           { return p.chunks }
+
+// textFile implements diff.File with precomputed metadata
+//
+// textFile 使用预先计算的元数据实现 diff.File
+type textFile struct {
+	path string
+	mode filemode.FileMode
+	hash plumbing.Hash
+}
+
+func (f *textFile) Hash() plumbing.Hash     { return f.hash }
+func (f *textFile) Mode() filemode.FileMode { return f.mode }
+func (f *textFile) Path() string            { return f.path }
+
+// textChunk implements diff.Chunk
+//
+// textChunk 实现 diff.Chunk
+type textChunk struct {
+	content   string
+	operation fdiff.Operation
+}
+
+func (c *textChunk) Content() string       { return c.content }
+func (c *textChunk) Type() fdiff.Operation { return c.operation }
@@ -0,0 +1,112 @@
+package gogitchange_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xlan/gogit/gogitchange"
+	"github.com/stretchr/testify/require"
+	"github.com/yyle88/must"
+	"github.com/yyle88/rese"
+)
+
+// upperTransformer turns content into upper case, used to make visible changes
+//
+// upperTransformer 将内容转换为大写，用于产生可见的变更
+func upperTransformer(file gogitchange.ChangedFile, content []byte) ([]byte, error) {
+	return bytes.ToUpper(content), nil
+}
+
+// TestChangedFileManager_Transform_DryRun verifies dry run previews diffs without writing
+// Should leave files untouched and skip files whose content stays the same
+//
+// TestChangedFileManager_Transform_DryRun 验证试运行仅预览差异而不写入
+// 应该保持文件不变，并跳过内容未变化的文件
+func TestChangedFileManager_Transform_DryRun(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	writeTestFile(root, "notes.txt", "one\ntwo\n")
+	writeTestFile(root, "UPPER.txt", "SAME\n")
+
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	report, err := manager.Transform(gogitchange.NewMatchOptions(), gogitchange.NewTransformOptions().DryRun(true), upperTransformer)
+	require.NoError(t, err)
+	t.Log(report.Diff())
+
+	require.Len(t, report.Results, 1)
+	result := report.Results[0]
+	require.Equal(t, "notes.txt", result.File.RelativePath)
+	require.False(t, result.Applied)
+	require.Contains(t, result.Diff, "--- a/notes.txt\n+++ b/notes.txt\n")
+	require.Contains(t, result.Diff, "-one\n-two\n+ONE\n+TWO\n")
+	require.Equal(t, "one\ntwo\n", string(rese.V1(os.ReadFile(filepath.Join(root, "notes.txt")))))
+}
+
+// TestChangedFileManager_Transform_Apply verifies applied writes keep the file mode
+//
+// TestChangedFileManager_Transform_Apply 验证应用写入时保留文件模式
+func TestChangedFileManager_Transform_Apply(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	writeTestFile(root, "run.sh", "echo hi\n")
+	must.Done(os.Chmod(filepath.Join(root, "run.sh"), 0755))
+
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	report, err := manager.Transform(gogitchange.NewMatchOptions(), gogitchange.NewTransformOptions(), upperTransformer)
+	require.NoError(t, err)
+
+	require.Len(t, report.Results, 1)
+	require.True(t, report.Results[0].Applied)
+	require.Equal(t, "ECHO HI\n", string(rese.V1(os.ReadFile(filepath.Join(root, "run.sh")))))
+	require.Equal(t, os.FileMode(0755), rese.V1(os.Stat(filepath.Join(root, "run.sh"))).Mode().Perm())
+
+	entries := rese.V1(os.ReadDir(root))
+	for _, entry := range entries {
+		require.NotContains(t, entry.Name(), ".tmp-")
+	}
+}
+
+// TestUnifiedDiff verifies hunks keep the configured count of context lines
+//
+// TestUnifiedDiff 验证差异块保留配置的上下文行数
+func TestUnifiedDiff(t *testing.T) {
+	before := []byte("a\nb\nc\nd\ne\nf\n")
+	after := []byte("a\nb\nc\nD\ne\nf\n")
+
+	text, err := gogitchange.UnifiedDiff("x.txt", 0644, before, after, 1)
+	require.NoError(t, err)
+	t.Log(text)
+	require.Contains(t, text, "@@ -3,3 +3,3 @@")
+	require.Contains(t, text, " c\n-d\n+D\n e\n")
+
+	text, err = gogitchange.UnifiedDiff("x.txt", 0644, before, before, 1)
+	require.NoError(t, err)
+	require.Empty(t, text)
+}
+
+// TestChangedFileManager_Transform_Symlink verifies tracked symlinks are neither transformed nor replaced
+// WriteFileAtomic on a link writes the target and keeps the link
+//
+// TestChangedFileManager_Transform_Symlink 验证被跟踪的符号链接既不被转换也不被替换
+// 对链接调用 WriteFileAtomic 时写入目标并保留链接
+func TestChangedFileManager_Transform_Symlink(t *testing.T) {
+	root, _, tree := setupTestRepo(t, map[string]string{"README.md": "# Demo\n"})
+	writeTestFile(root, "target.txt", "target\n")
+	if err := os.Symlink("target.txt", filepath.Join(root, "link.txt")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	manager := gogitchange.NewChangedFileManager(root, tree)
+	report, err := manager.Transform(gogitchange.NewMatchOptions(), gogitchange.NewTransformOptions(), upperTransformer)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 1)
+	require.Equal(t, "target.txt", report.Results[0].File.RelativePath)
+
+	linkPath := filepath.Join(root, "link.txt")
+	require.NotZero(t, rese.V1(os.Lstat(linkPath)).Mode()&os.ModeSymlink)
+	require.Equal(t, "target.txt", rese.V1(os.Readlink(linkPath)))
+
+	require.NoError(t, gogitchange.WriteFileAtomic(linkPath, []byte("through link\n"), 0644))
+	require.NotZero(t, rese.V1(os.Lstat(linkPath)).Mode()&os.ModeSymlink)
+	require.Equal(t, "through link\n", string(rese.V1(os.ReadFile(filepath.Join(root, "target.txt")))))
+}
@@ -8,6 +8,8 @@ package gogit
 import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-xlan/gogit/gogitassist"
 	"github.com/yyle88/sure"
 )
 
@@ -24,6 +26,14 @@ func (T *Client88Must) Tree() (res *git.Worktree) {
 	res = T.c.Tree()
 	return res
 }
+func (T *Client88Must) Root() (res string) {
+	res = T.c.Root()
+	return res
+}
+func (T *Client88Must) SubPath() (res string) {
+	res = T.c.SubPath()
+	return res
+}
 func (T *Client88Must) AddAll() {
 	err := T.c.AddAll()
 	sure.Must(err)
@@ -33,6 +43,16 @@ func (T *Client88Must) Status() (res git.Status) {
 	sure.Must(err1)
 	return res
 }
+func (T *Client88Must) StatusIn(prefix string) (res git.Status) {
+	res, err1 := T.c.StatusIn(prefix)
+	sure.Must(err1)
+	return res
+}
+func (T *Client88Must) FastStatus() (res git.Status) {
+	res, err1 := T.c.FastStatus()
+	sure.Must(err1)
+	return res
+}
 func (T *Client88Must) CommitAll(info *CommitInfo) (res string) {
 	res, err1 := T.c.CommitAll(info)
 	sure.Must(err1)
@@ -53,11 +73,38 @@ func (T *Client88Must) IsLatestCommitPushed() (res bool) {
 	sure.Must(err1)
 	return res
 }
+func (T *Client88Must) SetAuth(auth gogitassist.AuthProvider) (res *Client) {
+	res = T.c.SetAuth(auth)
+	return res
+}
+func (T *Client88Must) SetHostKeyPolicy(hostKeyPolicy *gogitassist.HostKeyPolicy) (res *Client) {
+	res = T.c.SetHostKeyPolicy(hostKeyPolicy)
+	return res
+}
+func (T *Client88Must) AuthForURL(remoteURL string) (res transport.AuthMethod) {
+	res, err1 := T.c.AuthForURL(remoteURL)
+	sure.Must(err1)
+	return res
+}
+func (T *Client88Must) AuthForRemote(remoteName string) (res transport.AuthMethod) {
+	res, err1 := T.c.AuthForRemote(remoteName)
+	sure.Must(err1)
+	return res
+}
 func (T *Client88Must) GetCurrentBranch() (res string) {
 	res, err1 := T.c.GetCurrentBranch()
 	sure.Must(err1)
 	return res
 }
+func (T *Client88Must) SetUpstream(branch string, remote string, remoteBranch string) {
+	err := T.c.SetUpstream(branch, remote, remoteBranch)
+	sure.Must(err)
+}
+func (T *Client88Must) GetUpstream(branch string) (res *gogitassist.Upstream) {
+	res, err1 := T.c.GetUpstream(branch)
+	sure.Must(err1)
+	return res
+}
 func (T *Client88Must) GetLatestCommit() (res *object.Commit) {
 	res, err1 := T.c.GetLatestCommit()
 	sure.Must(err1)
@@ -68,11 +115,36 @@ func (T *Client88Must) HasChanges() (res bool) {
 	sure.Must(err1)
 	return res
 }
+func (T *Client88Must) HasChangesIn(prefix string) (res bool) {
+	res, err1 := T.c.HasChangesIn(prefix)
+	sure.Must(err1)
+	return res
+}
 func (T *Client88Must) GetRemoteURL(remoteName string) (res string) {
 	res, err1 := T.c.GetRemoteURL(remoteName)
 	sure.Must(err1)
 	return res
 }
+func (T *Client88Must) GetRemoteFetchURL(remoteName string) (res string) {
+	res, err1 := T.c.GetRemoteFetchURL(remoteName)
+	sure.Must(err1)
+	return res
+}
+func (T *Client88Must) GetRemotePushURL(remoteName string) (res string) {
+	res, err1 := T.c.GetRemotePushURL(remoteName)
+	sure.Must(err1)
+	return res
+}
+func (T *Client88Must) GetParsedRemoteURL(remoteName string) (res *gogitassist.RemoteURL) {
+	res, err1 := T.c.GetParsedRemoteURL(remoteName)
+	sure.Must(err1)
+	return res
+}
+func (T *Client88Must) ListRemotes() (res []*gogitassist.RemoteInfo) {
+	res, err1 := T.c.ListRemotes()
+	sure.Must(err1)
+	return res
+}
 func (T *Client88Must) GetFirstRemoteURL() (res string) {
 	res, err1 := T.c.GetFirstRemoteURL()
 	sure.Must(err1)